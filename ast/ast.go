@@ -3,6 +3,8 @@ package ast
 import (
 	"bytes"
 	"gibbon/token"
+	"math/big"
+	"strings"
 )
 
 type Node interface {
@@ -54,12 +56,33 @@ func (i *Identifier) String() string       { return i.Value }
 type IntegerLiteral struct {
 	Token token.Token // variable name / token.IDENT
 	Value int64
+	Big   *big.Int // the value of literals beyond the range of int64, nil otherwise
 }
 
 func (i *IntegerLiteral) expressionNode()      {}
 func (i *IntegerLiteral) TokenLiteral() string { return i.Token.Literal }
 func (i *IntegerLiteral) String() string       { return i.Token.Literal }
 
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+}
+
+func (f *FloatLiteral) expressionNode()      {}
+func (f *FloatLiteral) TokenLiteral() string { return f.Token.Literal }
+func (f *FloatLiteral) String() string       { return f.Token.Literal }
+
+// DecimalLiteral is a number with a "d" suffix, worth Unscaled / 10**Scale.
+type DecimalLiteral struct {
+	Token    token.Token
+	Unscaled *big.Int
+	Scale    int // number of digits after the decimal point
+}
+
+func (d *DecimalLiteral) expressionNode()      {}
+func (d *DecimalLiteral) TokenLiteral() string { return d.Token.Literal }
+func (d *DecimalLiteral) String() string       { return d.Token.Literal }
+
 type Boolean struct {
 	Token token.Token // variable name / token.IDENT
 	Value bool
@@ -70,10 +93,12 @@ func (b *Boolean) TokenLiteral() string { return b.Token.Literal }
 func (b *Boolean) String() string       { return b.Token.Literal }
 
 // let <identifier> = <expression>;
+// const <identifier> = <expression>;
 type LetStatement struct {
-	Token token.Token // token.LET
-	Name  *Identifier
-	Value Expression
+	Token    token.Token // token.LET or token.CONST
+	Name     *Identifier
+	Value    Expression
+	Constant bool // whether Name can never be assigned again
 }
 
 func (ls *LetStatement) statementNode()       {}
@@ -162,3 +187,303 @@ func (ie *InfixExpression) String() string {
 	out.WriteString(")")
 	return out.String()
 }
+
+type NullLiteral struct {
+	Token token.Token // the 'null' token
+}
+
+func (nl *NullLiteral) expressionNode()      {}
+func (nl *NullLiteral) TokenLiteral() string { return nl.Token.Literal }
+func (nl *NullLiteral) String() string       { return nl.Token.Literal }
+
+type StringLiteral struct {
+	Token token.Token // token.STRING or a part of an interpolated string, delimiters and escapes included
+	Value string
+}
+
+func (sl *StringLiteral) expressionNode()      {}
+func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *StringLiteral) String() string       { return sl.Token.Literal }
+
+// "...${<expression>}...${<expression>}..."
+type InterpolatedString struct {
+	Token token.Token  // the token.STRING_HEAD token
+	Parts []Expression // *StringLiteral parts of the string, each followed by an embedded expression but the last
+}
+
+func (is *InterpolatedString) expressionNode()      {}
+func (is *InterpolatedString) TokenLiteral() string { return is.Token.Literal }
+func (is *InterpolatedString) String() string {
+	var out bytes.Buffer
+
+	for _, part := range is.Parts {
+		out.WriteString(part.String())
+	}
+
+	return out.String()
+}
+
+// [<expression>, <expression>, ...]
+type ArrayLiteral struct {
+	Token    token.Token // the '[' token
+	Elements []Expression
+}
+
+func (al *ArrayLiteral) expressionNode()      {}
+func (al *ArrayLiteral) TokenLiteral() string { return al.Token.Literal }
+func (al *ArrayLiteral) String() string {
+	elements := []string{}
+	for _, element := range al.Elements {
+		elements = append(elements, element.String())
+	}
+
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+type HashPair struct {
+	Key   Expression
+	Value Expression
+}
+
+// {<expression>: <expression>, ...}
+type HashLiteral struct {
+	Token token.Token // the '{' token
+	Pairs []HashPair  // in source order
+}
+
+func (hl *HashLiteral) expressionNode()      {}
+func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Literal }
+func (hl *HashLiteral) String() string {
+	pairs := []string{}
+	for _, pair := range hl.Pairs {
+		pairs = append(pairs, pair.Key.String()+": "+pair.Value.String())
+	}
+
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// <expression>[<expression>]
+// <expression>?[<expression>]
+// <expression>?.<identifier>
+type IndexExpression struct {
+	Token    token.Token // the '[', '?[' or '?.' token
+	Left     Expression
+	Index    Expression // an *Identifier naming the hash key after '?.'
+	Optional bool       // whether a null Left makes the whole chain null
+}
+
+func (ie *IndexExpression) expressionNode()      {}
+func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IndexExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("(")
+	out.WriteString(ie.Left.String())
+	out.WriteString(ie.Token.Literal)
+	out.WriteString(ie.Index.String())
+	if ie.Token.Type != token.OPTIONAL_DOT {
+		out.WriteString("]")
+	}
+	out.WriteString(")")
+	return out.String()
+}
+
+// fn(<identifier>, <identifier>, ...) <block>
+type FunctionLiteral struct {
+	Token      token.Token // the 'fn' token
+	Parameters []*Identifier
+	Body       *BlockStatement
+}
+
+func (fl *FunctionLiteral) expressionNode()      {}
+func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FunctionLiteral) String() string {
+	parameters := []string{}
+	for _, parameter := range fl.Parameters {
+		parameters = append(parameters, parameter.String())
+	}
+
+	return fl.TokenLiteral() + "(" + strings.Join(parameters, ", ") + ") " + fl.Body.String()
+}
+
+// <expression>(<expression>, <expression>, ...)
+type CallExpression struct {
+	Token     token.Token // the '(' token
+	Function  Expression  // an identifier, a function literal or any expression giving a function
+	Arguments []Expression
+	Grouped   bool // whether the call is written in parentheses, which keeps a pipe from extending it
+}
+
+func (ce *CallExpression) expressionNode()      {}
+func (ce *CallExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *CallExpression) String() string {
+	arguments := []string{}
+	for _, argument := range ce.Arguments {
+		arguments = append(arguments, argument.String())
+	}
+
+	return ce.Function.String() + "(" + strings.Join(arguments, ", ") + ")"
+}
+
+// <expression> |> <call expression>
+// <expression> |> <expression>
+//
+// The left operand is passed as the first argument of the call on the right,
+// so "a |> f(b)" calls f(a, b), and "a |> f" calls f(a). A call in
+// parentheses is not extended, so "a |> (f(b))" calls what f(b) gives with a.
+type PipeExpression struct {
+	Token token.Token // the '|>' token
+	Left  Expression
+	Right Expression
+}
+
+func (pe *PipeExpression) expressionNode()      {}
+func (pe *PipeExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PipeExpression) String() string {
+	right := pe.Right.String()
+	if call, ok := pe.Right.(*CallExpression); ok && call.Grouped {
+		right = "(" + right + ")"
+	}
+
+	return "(" + pe.Left.String() + " |> " + right + ")"
+}
+
+// <expression>..<expression>
+// <expression>..=<expression>
+type RangeExpression struct {
+	Token     token.Token // the '..' or '..=' token
+	Start     Expression
+	End       Expression
+	Inclusive bool // whether End belongs to the range
+}
+
+func (re *RangeExpression) expressionNode()      {}
+func (re *RangeExpression) TokenLiteral() string { return re.Token.Literal }
+func (re *RangeExpression) String() string {
+	return "(" + re.Start.String() + re.Token.Literal + re.End.String() + ")"
+}
+
+// <identifier or index expression> <assignment operator> <expression>
+type AssignExpression struct {
+	Token    token.Token // the assignment operator token, such as '=' or '+='
+	Target   Expression  // *Identifier or *IndexExpression
+	Operator string
+	Value    Expression
+}
+
+func (ae *AssignExpression) expressionNode()      {}
+func (ae *AssignExpression) TokenLiteral() string { return ae.Token.Literal }
+func (ae *AssignExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("(")
+	out.WriteString(ae.Target.String())
+	out.WriteString(" " + ae.Operator + " ")
+	out.WriteString(ae.Value.String())
+	out.WriteString(")")
+	return out.String()
+}
+
+// { <statement> <statement> ... }
+type BlockStatement struct {
+	Token      token.Token // the '{' token
+	Statements []Statement
+}
+
+func (bs *BlockStatement) statementNode()       {}
+func (bs *BlockStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BlockStatement) String() string {
+	if len(bs.Statements) == 0 {
+		return "{}"
+	}
+
+	statements := []string{}
+	for _, s := range bs.Statements {
+		statements = append(statements, s.String())
+	}
+
+	return "{ " + strings.Join(statements, " ") + " }"
+}
+
+// if (<expression>) <block> else <block>
+type IfExpression struct {
+	Token       token.Token // the 'if' token
+	Condition   Expression
+	Consequence *BlockStatement
+	Alternative *BlockStatement // nil without an else branch
+}
+
+func (ie *IfExpression) expressionNode()      {}
+func (ie *IfExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IfExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("if ")
+	out.WriteString(ie.Condition.String())
+	out.WriteString(" ")
+	out.WriteString(ie.Consequence.String())
+
+	if ie.Alternative != nil {
+		out.WriteString(" else ")
+		out.WriteString(ie.Alternative.String())
+	}
+
+	return out.String()
+}
+
+// while (<expression>) <block>
+type WhileExpression struct {
+	Token     token.Token // the 'while' token
+	Condition Expression
+	Body      *BlockStatement
+}
+
+func (we *WhileExpression) expressionNode()      {}
+func (we *WhileExpression) TokenLiteral() string { return we.Token.Literal }
+func (we *WhileExpression) String() string {
+	return "while " + we.Condition.String() + " " + we.Body.String()
+}
+
+// for (<identifier> in <expression>) <block>
+// for (<identifier>, <identifier> in <expression>) <block>
+type ForInExpression struct {
+	Token    token.Token // the 'for' token
+	Key      *Identifier // nil when only the values are bound
+	Value    *Identifier
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fe *ForInExpression) expressionNode()      {}
+func (fe *ForInExpression) TokenLiteral() string { return fe.Token.Literal }
+func (fe *ForInExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("for (")
+
+	if fe.Key != nil {
+		out.WriteString(fe.Key.String() + ", ")
+	}
+
+	out.WriteString(fe.Value.String())
+	out.WriteString(" in ")
+	out.WriteString(fe.Iterable.String())
+	out.WriteString(") ")
+	out.WriteString(fe.Body.String())
+
+	return out.String()
+}
+
+// break;
+type BreakStatement struct {
+	Token token.Token // the 'break' token
+}
+
+func (bs *BreakStatement) statementNode()       {}
+func (bs *BreakStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BreakStatement) String() string       { return bs.Token.Literal + ";" }
+
+// continue;
+type ContinueStatement struct {
+	Token token.Token // the 'continue' token
+}
+
+func (cs *ContinueStatement) statementNode()       {}
+func (cs *ContinueStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ContinueStatement) String() string       { return cs.Token.Literal + ";" }
@@ -0,0 +1,167 @@
+// Package cst holds the lossless syntax tree: every token read from the
+// source, trivia included, grouped by statement and bracket nesting, so that
+// printing the tree gives back the original source byte-for-byte.
+package cst
+
+import (
+	"gibbon/ast"
+	"gibbon/token"
+	"strings"
+)
+
+type Element interface {
+	Tokens() []token.Token
+	writeTo(out *strings.Builder)
+}
+
+type Tree struct {
+	Statements []*Statement
+	EOF        token.Token // holds the trivia after the last statement
+}
+
+func (t *Tree) String() string {
+	var out strings.Builder
+
+	for _, s := range t.Statements {
+		s.writeTo(&out)
+	}
+	writeToken(&out, t.EOF)
+
+	return out.String()
+}
+
+func (t *Tree) Tokens() []token.Token {
+	tokens := []token.Token{}
+
+	for _, s := range t.Statements {
+		tokens = append(tokens, s.Tokens()...)
+	}
+
+	return append(tokens, t.EOF)
+}
+
+// Statement is the source of one statement parsed into the AST.
+type Statement struct {
+	Node     ast.Statement // nil when the statement could not be parsed
+	Elements []Element
+}
+
+// NewStatement groups the tokens node was parsed from by their brackets.
+func NewStatement(node ast.Statement, tokens []token.Token) *Statement {
+	elements, _ := group(tokens, false)
+	return &Statement{Node: node, Elements: elements}
+}
+
+func (s *Statement) String() string {
+	var out strings.Builder
+	s.writeTo(&out)
+	return out.String()
+}
+
+func (s *Statement) Tokens() []token.Token {
+	return elementTokens(s.Elements)
+}
+
+func (s *Statement) writeTo(out *strings.Builder) {
+	for _, e := range s.Elements {
+		e.writeTo(out)
+	}
+}
+
+// Leaf is a single token.
+type Leaf struct {
+	Token token.Token
+}
+
+func (l *Leaf) Tokens() []token.Token        { return []token.Token{l.Token} }
+func (l *Leaf) writeTo(out *strings.Builder) { writeToken(out, l.Token) }
+
+// Group is a bracketed run of elements, such as a parameter list or a block.
+type Group struct {
+	Open     *Leaf
+	Elements []Element
+	Close    *Leaf // nil when the source ends before the group is closed
+}
+
+func (g *Group) Tokens() []token.Token {
+	tokens := append(g.Open.Tokens(), elementTokens(g.Elements)...)
+
+	if g.Close != nil {
+		tokens = append(tokens, g.Close.Token)
+	}
+
+	return tokens
+}
+
+func (g *Group) writeTo(out *strings.Builder) {
+	g.Open.writeTo(out)
+
+	for _, e := range g.Elements {
+		e.writeTo(out)
+	}
+
+	if g.Close != nil {
+		g.Close.writeTo(out)
+	}
+}
+
+var closingTypes = map[token.TokenType]token.TokenType{
+	token.LPAREN:            token.RPAREN,
+	token.LBRACE:            token.RBRACE,
+	token.LBRACKET:          token.RBRACKET,
+	token.OPTIONAL_LBRACKET: token.RBRACKET,
+}
+
+// group nests tokens by their brackets. Nested groups stop at the first
+// closing bracket they meet and return it, along with the tokens after it,
+// for the enclosing group to match; unmatched closing brackets at the top
+// level are kept as plain leaves.
+func group(tokens []token.Token, nested bool) ([]Element, []token.Token) {
+	elements := []Element{}
+
+	for len(tokens) > 0 {
+		tok := tokens[0]
+		if nested && isClosing(tok.Type) {
+			return elements, tokens
+		}
+		tokens = tokens[1:]
+
+		closingType, opens := closingTypes[tok.Type]
+		if !opens {
+			elements = append(elements, &Leaf{Token: tok})
+			continue
+		}
+
+		g := &Group{Open: &Leaf{Token: tok}}
+		g.Elements, tokens = group(tokens, true)
+
+		if len(tokens) > 0 && tokens[0].Type == closingType {
+			g.Close = &Leaf{Token: tokens[0]}
+			tokens = tokens[1:]
+		}
+
+		elements = append(elements, g)
+	}
+
+	return elements, tokens
+}
+
+func isClosing(t token.TokenType) bool {
+	return t == token.RPAREN || t == token.RBRACE || t == token.RBRACKET
+}
+
+func elementTokens(elements []Element) []token.Token {
+	tokens := []token.Token{}
+
+	for _, e := range elements {
+		tokens = append(tokens, e.Tokens()...)
+	}
+
+	return tokens
+}
+
+func writeToken(out *strings.Builder, t token.Token) {
+	out.WriteString(t.LeadingTrivia)
+	out.WriteString(t.Literal)
+	out.WriteString(t.TrailingTrivia)
+}
@@ -0,0 +1,83 @@
+package cst
+
+import (
+	"gibbon/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatementGroupsBrackets(t *testing.T) {
+	assert := assert.New(t)
+
+	tokens := []token.Token{
+		{Type: token.IDENT, Literal: "f"},
+		{Type: token.LPAREN, Literal: "("},
+		{Type: token.IDENT, Literal: "a", TrailingTrivia: " "},
+		{Type: token.LBRACE, Literal: "{"},
+		{Type: token.RBRACE, Literal: "}"},
+		{Type: token.RPAREN, Literal: ")"},
+		{Type: token.RBRACE, Literal: "}", LeadingTrivia: "\n"},
+	}
+
+	statement := NewStatement(nil, tokens)
+
+	if !assert.Len(statement.Elements, 3) {
+		t.FailNow()
+	}
+
+	call, ok := statement.Elements[1].(*Group)
+	if !assert.Truef(ok, "element not of type *Group, got=%T", statement.Elements[1]) {
+		t.FailNow()
+	}
+
+	assert.Equal("(", call.Open.Token.Literal)
+	assert.Equal(")", call.Close.Token.Literal)
+	assert.Len(call.Elements, 2)
+	assert.IsType(&Group{}, call.Elements[1])
+	assert.IsType(&Leaf{}, statement.Elements[2])
+
+	assert.Equal(tokens, statement.Tokens())
+	assert.Equal("f(a {})\n}", statement.String())
+}
+
+func TestStatementKeepsUnclosedGroups(t *testing.T) {
+	assert := assert.New(t)
+
+	tokens := []token.Token{
+		{Type: token.LPAREN, Literal: "("},
+		{Type: token.LBRACE, Literal: "{"},
+		{Type: token.RPAREN, Literal: ")"},
+	}
+
+	statement := NewStatement(nil, tokens)
+
+	assert.Len(statement.Elements, 1)
+	assert.Equal(tokens, statement.Tokens())
+	assert.Equal("({)", statement.String())
+}
+
+func TestOptionalIndexOpensGroup(t *testing.T) {
+	assert := assert.New(t)
+
+	tokens := []token.Token{
+		{Type: token.IDENT, Literal: "xs"},
+		{Type: token.OPTIONAL_LBRACKET, Literal: "?["},
+		{Type: token.INT, Literal: "0"},
+		{Type: token.RBRACKET, Literal: "]"},
+	}
+
+	statement := NewStatement(nil, tokens)
+
+	if !assert.Len(statement.Elements, 2) {
+		t.FailNow()
+	}
+
+	index, ok := statement.Elements[1].(*Group)
+	if !assert.Truef(ok, "element not of type *Group, got=%T", statement.Elements[1]) {
+		t.FailNow()
+	}
+
+	assert.Equal("?[", index.Open.Token.Literal)
+	assert.Equal("]", index.Close.Token.Literal)
+}
@@ -0,0 +1,261 @@
+package evaluator
+
+import (
+	"fmt"
+	"gibbon/object"
+	"gibbon/token"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// newBuiltins returns the builtin functions and constants identifiers resolve
+// to when they are not bound in the environment, keyed by name.
+func (e *Evaluator) newBuiltins() map[string]object.Object {
+	builtins := map[string]object.Object{}
+	register := func(name string, fn object.BuiltinFunction) {
+		builtins[name] = &object.Builtin{Name: name, Fn: fn}
+	}
+
+	register("len", builtinLen)
+	register("puts", e.builtinPuts)
+	register("first", builtinFirst)
+	register("last", builtinLast)
+	register("rest", builtinRest)
+	register("push", builtinPush)
+	register("type", builtinType)
+	register("str", builtinStr)
+
+	register("map", e.builtinMap)
+	register("filter", e.builtinFilter)
+	register("reduce", e.builtinReduce)
+	register("sort", e.builtinSort)
+	register("sort_by", e.builtinSortBy)
+	register("zip", e.builtinZip)
+	register("enumerate", e.builtinEnumerate)
+	register("any", e.builtinAny)
+	register("all", e.builtinAll)
+	register("flatten", e.builtinFlatten)
+
+	register("split", builtinSplit)
+	register("join", builtinJoin)
+	register("trim", builtinTrim)
+	register("upper", builtinUpper)
+	register("lower", builtinLower)
+	register("replace", builtinReplace)
+	register("contains", builtinContains)
+	register("starts_with", builtinStartsWith)
+	register("ends_with", builtinEndsWith)
+	register("index_of", builtinIndexOf)
+	register("repeat", builtinRepeat)
+	register("pad_left", builtinPadLeft)
+	register("pad_right", builtinPadRight)
+	register("chars", builtinChars)
+	register("substring", builtinSubstring)
+
+	builtins["PI"] = &object.Float{Value: math.Pi}
+	builtins["E"] = &object.Float{Value: math.E}
+	register("abs", e.builtinAbs)
+	register("min", e.builtinMin)
+	register("max", e.builtinMax)
+	register("pow", e.builtinPow)
+	register("sqrt", builtinSqrt)
+	register("floor", builtinFloor)
+	register("ceil", builtinCeil)
+	register("round", e.builtinRound)
+	register("clamp", e.builtinClamp)
+	register("sin", floatFunction("sin", math.Sin, nil))
+	register("cos", floatFunction("cos", math.Cos, nil))
+	register("tan", floatFunction("tan", math.Tan, nil))
+	register("asin", floatFunction("asin", math.Asin, withinUnitRange))
+	register("acos", floatFunction("acos", math.Acos, withinUnitRange))
+	register("atan", floatFunction("atan", math.Atan, nil))
+	register("atan2", builtinAtan2)
+	register("exp", floatFunction("exp", math.Exp, nil))
+	register("log", builtinLog)
+	register("log2", floatFunction("log2", math.Log2, positive))
+	register("log10", floatFunction("log10", math.Log10, positive))
+
+	register("json_parse", builtinJSONParse)
+	register("json_stringify", builtinJSONStringify)
+
+	register("re_compile", e.builtinReCompile)
+	register("re_match", e.builtinReMatch)
+	register("re_find_all", e.builtinReFindAll)
+	register("re_replace", e.builtinReReplace)
+	register("re_split", e.builtinReSplit)
+
+	register("now", e.builtinNow)
+	register("time_parse", builtinTimeParse)
+	register("time_format", builtinTimeFormat)
+	register("duration", builtinDuration)
+
+	return builtins
+}
+
+// len(value) is the number of characters of a string, elements of an array
+// or pairs of a hash.
+func builtinLen(at token.Token, arguments ...object.Object) object.Object {
+	if err := checkArity(at, "len", arguments, 1, 1); err != nil {
+		return err
+	}
+
+	switch argument := arguments[0].(type) {
+	case *object.String:
+		return &object.Integer{Value: int64(utf8.RuneCountInString(argument.Value))}
+	case *object.Array:
+		return &object.Integer{Value: int64(len(argument.Elements))}
+	case *object.Hash:
+		return &object.Integer{Value: int64(len(argument.Keys))}
+	}
+
+	return argumentTypeError(at, "len", 1, arguments[0], object.STRING_OBJ, object.ARRAY_OBJ, object.HASH_OBJ)
+}
+
+// puts(values...) writes each value on its own line.
+func (e *Evaluator) builtinPuts(at token.Token, arguments ...object.Object) object.Object {
+	for _, argument := range arguments {
+		fmt.Fprintln(e.output, argument.Inspect())
+	}
+
+	return NULL
+}
+
+// first(array) is the first element of array, or null if it is empty.
+func builtinFirst(at token.Token, arguments ...object.Object) object.Object {
+	array, err := arrayArgument(at, "first", arguments)
+	if err != nil {
+		return err
+	}
+
+	if len(array.Elements) == 0 {
+		return NULL
+	}
+	return array.Elements[0]
+}
+
+// last(array) is the last element of array, or null if it is empty.
+func builtinLast(at token.Token, arguments ...object.Object) object.Object {
+	array, err := arrayArgument(at, "last", arguments)
+	if err != nil {
+		return err
+	}
+
+	if len(array.Elements) == 0 {
+		return NULL
+	}
+	return array.Elements[len(array.Elements)-1]
+}
+
+// rest(array) is a new array of every element of array but the first, or
+// null if it is empty.
+func builtinRest(at token.Token, arguments ...object.Object) object.Object {
+	array, err := arrayArgument(at, "rest", arguments)
+	if err != nil {
+		return err
+	}
+
+	if len(array.Elements) == 0 {
+		return NULL
+	}
+
+	elements := make([]object.Object, len(array.Elements)-1)
+	copy(elements, array.Elements[1:])
+	return &object.Array{Elements: elements}
+}
+
+// push(array, value) appends value to array, and gives array back.
+func builtinPush(at token.Token, arguments ...object.Object) object.Object {
+	if err := checkArity(at, "push", arguments, 2, 2); err != nil {
+		return err
+	}
+
+	array, ok := arguments[0].(*object.Array)
+	if !ok {
+		return argumentTypeError(at, "push", 1, arguments[0], object.ARRAY_OBJ)
+	}
+
+	array.Elements = append(array.Elements, arguments[1])
+	return array
+}
+
+// type(value) is the name of the type of value, such as "INTEGER".
+func builtinType(at token.Token, arguments ...object.Object) object.Object {
+	if err := checkArity(at, "type", arguments, 1, 1); err != nil {
+		return err
+	}
+
+	return &object.String{Value: string(arguments[0].Type())}
+}
+
+// str(value) is value as a string, written as it is shown.
+func builtinStr(at token.Token, arguments ...object.Object) object.Object {
+	if err := checkArity(at, "str", arguments, 1, 1); err != nil {
+		return err
+	}
+
+	if str, ok := arguments[0].(*object.String); ok {
+		return str
+	}
+	return &object.String{Value: arguments[0].Inspect()}
+}
+
+// ============ argument checks ============
+
+// checkArity returns an error unless there are between minimum and maximum
+// arguments to the builtin name, a negative maximum meaning no limit.
+func checkArity(at token.Token, name string, arguments []object.Object, minimum, maximum int) *object.Error {
+	count := len(arguments)
+	if count >= minimum && (maximum < 0 || count <= maximum) {
+		return nil
+	}
+
+	var want string
+	switch {
+	case maximum < 0:
+		want = fmt.Sprintf("at least %d", minimum)
+	case minimum == maximum:
+		want = fmt.Sprintf("%d", minimum)
+	default:
+		want = fmt.Sprintf("%d to %d", minimum, maximum)
+	}
+
+	return newError(at, "wrong number of arguments to %s: want=%s, got=%d", name, want, count)
+}
+
+// argumentTypeError reports that the argument at position, counting from 1,
+// of the builtin name is not of any of the expected types.
+func argumentTypeError(
+	at token.Token,
+	name string,
+	position int,
+	argument object.Object,
+	expected ...object.ObjectType,
+) *object.Error {
+	names := make([]string, len(expected))
+	for i, objectType := range expected {
+		names[i] = string(objectType)
+	}
+
+	want := names[len(names)-1]
+	if len(names) > 1 {
+		want = strings.Join(names[:len(names)-1], ", ") + " or " + want
+	}
+
+	return newError(at, "argument %d to %s must be %s, got %s", position, name, want, argument.Type())
+}
+
+// arrayArgument returns the only argument of the builtin name, which must be
+// an array.
+func arrayArgument(at token.Token, name string, arguments []object.Object) (*object.Array, *object.Error) {
+	if err := checkArity(at, name, arguments, 1, 1); err != nil {
+		return nil, err
+	}
+
+	array, ok := arguments[0].(*object.Array)
+	if !ok {
+		return nil, argumentTypeError(at, name, 1, arguments[0], object.ARRAY_OBJ)
+	}
+
+	return array, nil
+}
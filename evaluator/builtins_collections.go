@@ -0,0 +1,409 @@
+package evaluator
+
+import (
+	"gibbon/object"
+	"gibbon/token"
+	"sort"
+)
+
+// Higher-order builtins call back the functions they are given through
+// applyFunction, exactly as a call expression would, so user functions and
+// builtins can be passed alike. An error raised by a callback stops the
+// builtin and is returned as is.
+
+// map(iterable, fn) is a new array of fn(element) for every element.
+func (e *Evaluator) builtinMap(at token.Token, arguments ...object.Object) object.Object {
+	iterator, function, err := iterableAndFunctionArguments(at, "map", arguments)
+	if err != nil {
+		return err
+	}
+
+	mapped := []object.Object{}
+	for _, element, ok := iterator.Next(); ok; _, element, ok = iterator.Next() {
+		result := e.applyFunction(at, function, []object.Object{element})
+		if interrupts(result) {
+			return result
+		}
+		mapped = append(mapped, result)
+	}
+
+	return &object.Array{Elements: mapped}
+}
+
+// filter(iterable, fn) is a new array of the elements fn is truthy for.
+func (e *Evaluator) builtinFilter(at token.Token, arguments ...object.Object) object.Object {
+	iterator, function, err := iterableAndFunctionArguments(at, "filter", arguments)
+	if err != nil {
+		return err
+	}
+
+	kept := []object.Object{}
+	for _, element, ok := iterator.Next(); ok; _, element, ok = iterator.Next() {
+		result := e.applyFunction(at, function, []object.Object{element})
+		if interrupts(result) {
+			return result
+		}
+		if isTruthy(result) {
+			kept = append(kept, element)
+		}
+	}
+
+	return &object.Array{Elements: kept}
+}
+
+// reduce(iterable, fn, initial?) folds the elements into an accumulator,
+// updated to fn(accumulator, element) for each of them. Without an initial
+// value, the first element is the initial accumulator.
+func (e *Evaluator) builtinReduce(at token.Token, arguments ...object.Object) object.Object {
+	if err := checkArity(at, "reduce", arguments, 2, 3); err != nil {
+		return err
+	}
+
+	iterator, function, err := iterableAndFunctionArguments(at, "reduce", arguments[:2])
+	if err != nil {
+		return err
+	}
+
+	var accumulator object.Object
+	if len(arguments) == 3 {
+		accumulator = arguments[2]
+	} else if _, first, ok := iterator.Next(); ok {
+		accumulator = first
+	} else {
+		return newError(at, "reduce of an empty %s with no initial value", arguments[0].Type())
+	}
+
+	for _, element, ok := iterator.Next(); ok; _, element, ok = iterator.Next() {
+		accumulator = e.applyFunction(at, function, []object.Object{accumulator, element})
+		if interrupts(accumulator) {
+			return accumulator
+		}
+	}
+
+	return accumulator
+}
+
+// sort(array, fn?) is a new array of the elements of array in ascending
+// order, as told by "<" or by fn(a, b). fn either tells whether a goes before
+// b, or gives a negative, zero or positive integer. The sort is stable.
+func (e *Evaluator) builtinSort(at token.Token, arguments ...object.Object) object.Object {
+	if err := checkArity(at, "sort", arguments, 1, 2); err != nil {
+		return err
+	}
+
+	array, ok := arguments[0].(*object.Array)
+	if !ok {
+		return argumentTypeError(at, "sort", 1, arguments[0], object.ARRAY_OBJ)
+	}
+
+	less := func(a, b object.Object) object.Object { return e.evalLess(at, a, b) }
+	if len(arguments) == 2 {
+		comparator := arguments[1]
+		if !isCallable(comparator) {
+			return argumentTypeError(at, "sort", 2, comparator, object.FUNCTION_OBJ, object.BUILTIN_OBJ)
+		}
+		less = func(a, b object.Object) object.Object {
+			return e.compareWith(at, comparator, a, b)
+		}
+	}
+
+	return e.sortElements(array.Elements, array.Elements, less)
+}
+
+// sort_by(array, fn) is a new array of the elements of array in ascending
+// order of fn(element), compared with "<". The sort is stable.
+func (e *Evaluator) builtinSortBy(at token.Token, arguments ...object.Object) object.Object {
+	if err := checkArity(at, "sort_by", arguments, 2, 2); err != nil {
+		return err
+	}
+
+	array, ok := arguments[0].(*object.Array)
+	if !ok {
+		return argumentTypeError(at, "sort_by", 1, arguments[0], object.ARRAY_OBJ)
+	}
+	if !isCallable(arguments[1]) {
+		return argumentTypeError(at, "sort_by", 2, arguments[1], object.FUNCTION_OBJ, object.BUILTIN_OBJ)
+	}
+
+	// each key is computed once, ahead of the sort
+	keys := make([]object.Object, len(array.Elements))
+	for i, element := range array.Elements {
+		keys[i] = e.applyFunction(at, arguments[1], []object.Object{element})
+		if interrupts(keys[i]) {
+			return keys[i]
+		}
+	}
+
+	return e.sortElements(array.Elements, keys, func(a, b object.Object) object.Object {
+		return e.evalLess(at, a, b)
+	})
+}
+
+// zip(iterables...) is an array of arrays, the i-th one holding the i-th
+// element of every iterable. It is as long as the shortest iterable.
+func (e *Evaluator) builtinZip(at token.Token, arguments ...object.Object) object.Object {
+	if err := checkArity(at, "zip", arguments, 1, -1); err != nil {
+		return err
+	}
+
+	iterators := make([]object.Iterator, len(arguments))
+	for i, argument := range arguments {
+		iterator, err := iterableArgument(at, "zip", i+1, argument)
+		if err != nil {
+			return err
+		}
+		iterators[i] = iterator
+	}
+
+	rows := []object.Object{}
+	for {
+		row := make([]object.Object, len(iterators))
+		for i, iterator := range iterators {
+			_, element, ok := iterator.Next()
+			if !ok {
+				return &object.Array{Elements: rows}
+			}
+			row[i] = element
+		}
+		rows = append(rows, &object.Array{Elements: row})
+	}
+}
+
+// enumerate(iterable) is an array of [key, element] pairs, the key being the
+// index of the element in an array or a string, or its key in a hash.
+func (e *Evaluator) builtinEnumerate(at token.Token, arguments ...object.Object) object.Object {
+	if err := checkArity(at, "enumerate", arguments, 1, 1); err != nil {
+		return err
+	}
+
+	iterable, ok := arguments[0].(object.Iterable)
+	if !ok {
+		return argumentTypeError(at, "enumerate", 1, arguments[0], iterableTypes...)
+	}
+
+	pairs := []object.Object{}
+	iterator := iterable.Iterator()
+	for key, value, ok := iterator.Next(); ok; key, value, ok = iterator.Next() {
+		pairs = append(pairs, &object.Array{Elements: []object.Object{key, value}})
+	}
+
+	return &object.Array{Elements: pairs}
+}
+
+// any(iterable, fn) tells whether fn is truthy for some element, stopping at
+// the first one it is.
+func (e *Evaluator) builtinAny(at token.Token, arguments ...object.Object) object.Object {
+	return e.findTruthiness(at, "any", arguments, true)
+}
+
+// all(iterable, fn) tells whether fn is truthy for every element, stopping at
+// the first one it is not.
+func (e *Evaluator) builtinAll(at token.Token, arguments ...object.Object) object.Object {
+	return e.findTruthiness(at, "all", arguments, false)
+}
+
+// flatten(array, depth?) is a new array where the elements of nested arrays
+// replace the arrays, down to depth levels of nesting or all of them.
+func (e *Evaluator) builtinFlatten(at token.Token, arguments ...object.Object) object.Object {
+	if err := checkArity(at, "flatten", arguments, 1, 2); err != nil {
+		return err
+	}
+
+	array, ok := arguments[0].(*object.Array)
+	if !ok {
+		return argumentTypeError(at, "flatten", 1, arguments[0], object.ARRAY_OBJ)
+	}
+
+	depth := int64(-1)
+	if len(arguments) == 2 {
+		integer, ok := arguments[1].(*object.Integer)
+		if !ok {
+			return argumentTypeError(at, "flatten", 2, arguments[1], object.INTEGER_OBJ)
+		}
+		if integer.Value < 0 {
+			return newError(at, "negative flatten depth: %d", integer.Value)
+		}
+		depth = integer.Value
+	}
+
+	flattened, err := flattenArray(at, []object.Object{}, array, depth, map[*object.Array]bool{})
+	if err != nil {
+		return err
+	}
+
+	return &object.Array{Elements: flattened}
+}
+
+// ============ helpers ============
+
+// iterableTypes are the types of the objects for-in loops and collection
+// builtins iterate over.
+var iterableTypes = []object.ObjectType{object.ARRAY_OBJ, object.HASH_OBJ, object.STRING_OBJ, object.RANGE_OBJ}
+
+// iterableArgument returns an iterator over an iterable argument at position.
+// Arrays and hashes are iterated over as they are when the builtin is called,
+// so callbacks adding to them do not prolong the iteration, and ranges without
+// ever holding all their integers.
+func iterableArgument(at token.Token, name string, position int, argument object.Object) (object.Iterator, *object.Error) {
+	switch collection := argument.(type) {
+	case *object.Array:
+		argument = &object.Array{Elements: collection.Elements}
+	case *object.Hash:
+		argument = &object.Hash{Pairs: collection.Pairs, Keys: collection.Keys}
+	}
+
+	iterable, ok := argument.(object.Iterable)
+	if !ok {
+		return nil, argumentTypeError(at, name, position, argument, iterableTypes...)
+	}
+
+	return iterable.Iterator(), nil
+}
+
+// iterableAndFunctionArguments checks that the builtin name is given an
+// iterable and a function, and returns an iterator over the former.
+func iterableAndFunctionArguments(
+	at token.Token,
+	name string,
+	arguments []object.Object,
+) (object.Iterator, object.Object, *object.Error) {
+	if err := checkArity(at, name, arguments, 2, 2); err != nil {
+		return nil, nil, err
+	}
+
+	iterator, err := iterableArgument(at, name, 1, arguments[0])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !isCallable(arguments[1]) {
+		return nil, nil, argumentTypeError(at, name, 2, arguments[1], object.FUNCTION_OBJ, object.BUILTIN_OBJ)
+	}
+
+	return iterator, arguments[1], nil
+}
+
+// findTruthiness looks for an element fn is truthy for when truthy is set,
+// or falsy for otherwise, telling whether there is one as truthy is set.
+func (e *Evaluator) findTruthiness(at token.Token, name string, arguments []object.Object, truthy bool) object.Object {
+	iterator, function, err := iterableAndFunctionArguments(at, name, arguments)
+	if err != nil {
+		return err
+	}
+
+	for _, element, ok := iterator.Next(); ok; _, element, ok = iterator.Next() {
+		result := e.applyFunction(at, function, []object.Object{element})
+		if interrupts(result) {
+			return result
+		}
+		if isTruthy(result) == truthy {
+			return nativeBoolToBooleanObject(truthy)
+		}
+	}
+
+	return nativeBoolToBooleanObject(!truthy)
+}
+
+// sortElements returns a new array of elements stably sorted by their keys,
+// keys[i] being the key of elements[i], with less telling whether a key goes
+// before another. The first error less returns is returned instead.
+func (e *Evaluator) sortElements(elements, keys []object.Object, less func(a, b object.Object) object.Object) object.Object {
+	order := make([]int, len(elements))
+	for i := range order {
+		order[i] = i
+	}
+
+	var failure object.Object
+	sort.SliceStable(order, func(i, j int) bool {
+		if failure != nil {
+			return false
+		}
+
+		result := less(keys[order[i]], keys[order[j]])
+		if interrupts(result) {
+			failure = result
+			return false
+		}
+		return isTruthy(result)
+	})
+
+	if failure != nil {
+		return failure
+	}
+
+	sorted := make([]object.Object, len(elements))
+	for i, index := range order {
+		sorted[i] = elements[index]
+	}
+
+	return &object.Array{Elements: sorted}
+}
+
+// evalLess evaluates a < b, errors being located at the call site. Strings,
+// which "<" does not apply to, are ordered by code point.
+func (e *Evaluator) evalLess(at token.Token, a, b object.Object) object.Object {
+	if a, ok := a.(*object.String); ok {
+		if b, ok := b.(*object.String); ok {
+			return nativeBoolToBooleanObject(a.Value < b.Value)
+		}
+	}
+
+	operator := token.Token{Type: token.LT, Literal: token.LT.Lexeme(), Location: at.Location}
+	return e.evalInfixOperation(operator, a, b)
+}
+
+// compareWith tells whether a goes before b according to comparator, which
+// gives either a boolean or an integer whose sign orders a and b.
+func (e *Evaluator) compareWith(at token.Token, comparator, a, b object.Object) object.Object {
+	result := e.applyFunction(at, comparator, []object.Object{a, b})
+	if interrupts(result) {
+		return result
+	}
+
+	switch result := result.(type) {
+	case *object.Boolean:
+		return result
+	case *object.Integer:
+		return nativeBoolToBooleanObject(result.Value < 0)
+	}
+
+	return newError(at, "sort comparator must give %s or %s, got %s",
+		object.BOOLEAN_OBJ, object.INTEGER_OBJ, result.Type())
+}
+
+// flattenArray appends the elements of array to flattened, replacing arrays
+// with their elements down to depth levels, a negative depth meaning every
+// level. visiting holds the arrays being flattened, one of which met again
+// within itself is an error.
+func flattenArray(
+	at token.Token,
+	flattened []object.Object,
+	array *object.Array,
+	depth int64,
+	visiting map[*object.Array]bool,
+) ([]object.Object, *object.Error) {
+	if visiting[array] {
+		return nil, newError(at, "cannot flatten a cyclic %s", array.Type())
+	}
+	visiting[array] = true
+	defer delete(visiting, array)
+
+	for _, element := range array.Elements {
+		nested, ok := element.(*object.Array)
+		if !ok || depth == 0 {
+			flattened = append(flattened, element)
+			continue
+		}
+
+		var err *object.Error
+		if flattened, err = flattenArray(at, flattened, nested, depth-1, visiting); err != nil {
+			return nil, err
+		}
+	}
+
+	return flattened, nil
+}
+
+func isCallable(obj object.Object) bool {
+	return obj.Type() == object.FUNCTION_OBJ || obj.Type() == object.BUILTIN_OBJ
+}
@@ -0,0 +1,84 @@
+package evaluator
+
+import "testing"
+
+func TestCollectionBuiltins(t *testing.T) {
+	tests := []resultTest{
+		{`map([1, 2, 3], fn(x) { x * 2 })`, "[2, 4, 6]"},
+		{`map([], fn(x) { x })`, "[]"},
+		{`map(1..=3, fn(x) { x * x })`, "[1, 4, 9]"},
+		{`map("ab", fn(c) { c + c })`, "[aa, bb]"},
+		{`map({"a": 1, "b": 2}, fn(v) { v + 1 })`, "[2, 3]"},
+		{`map([[1], [2, 3]], len)`, "[1, 2]"},
+		{`[1, 2] |> map(fn(x) { x + 1 })`, "[2, 3]"},
+		{`let k = 10; map([1], fn(x) { x + k })`, "[11]"},
+		{`filter([1, 2, 3, 4], fn(x) { x % 2 == 0 })`, "[2, 4]"},
+		{`filter([1, null, 2, false], fn(x) { x })`, "[1, 2]"},
+		{`reduce([1, 2, 3, 4], fn(sum, x) { sum + x })`, 10},
+		{`reduce([1, 2, 3], fn(sum, x) { sum + x }, 10)`, 16},
+		{`reduce([], fn(sum, x) { sum + x }, 0)`, 0},
+		{`reduce(["a", "b"], fn(s, x) { x + s })`, "ba"},
+		{`sort([3, 1, 2])`, "[1, 2, 3]"},
+		{`sort(["b", "c", "a"])`, "[a, b, c]"},
+		{`sort([2, 1.5d, 1 << 70, 1])`, "[1, 1.5, 2, 1180591620717411303424]"},
+		{`sort([3, 1, 2], fn(a, b) { a > b })`, "[3, 2, 1]"},
+		{`sort([3, 1, 2], fn(a, b) { b - a })`, "[3, 2, 1]"},
+		{`let xs = [3, 1, 2]; sort(xs); xs`, "[3, 1, 2]"},
+		{`sort([[2, "a"], [1, "b"], [2, "c"], [1, "d"]], fn(a, b) { a[0] < b[0] })`, "[[1, b], [1, d], [2, a], [2, c]]"},
+		{`sort_by(["ccc", "a", "bb"], len)`, "[a, bb, ccc]"},
+		{`sort_by([[2, "a"], [1, "b"], [2, "c"]], first)`, "[[1, b], [2, a], [2, c]]"},
+		{`zip([1, 2, 3], ["a", "b"])`, "[[1, a], [2, b]]"},
+		{`zip([1, 2], 3..5, "xy")`, "[[1, 3, x], [2, 4, y]]"},
+		{`zip([1])`, "[[1]]"},
+		{`zip([1, 2], 0..9223372036854775807)`, "[[1, 0], [2, 1]]"},
+		{`zip(1..=9223372036854775807, "ab")`, "[[1, a], [2, b]]"},
+		{`enumerate(["a", "b"])`, "[[0, a], [1, b]]"},
+		{`enumerate({"k": 1})`, "[[k, 1]]"},
+		{`enumerate("hé")`, "[[0, h], [1, é]]"},
+		{`any([1, 2, 3], fn(x) { x > 2 })`, true},
+		{`any([], fn(x) { true })`, false},
+		{`all([1, 2, 3], fn(x) { x > 0 })`, true},
+		{`all([1, 2, 3], fn(x) { x > 1 })`, false},
+		{`all([], fn(x) { false })`, true},
+		{`let n = 0; any([1, 2, 3], fn(x) { n += 1; x == 2 }); n`, 2},
+		{`let n = 0; all([1, 2, 3], fn(x) { n += 1; x < 2 }); n`, 2},
+		{`any(0..9223372036854775807, fn(x) { x > 5 })`, true},
+		{`all(1..=9223372036854775807, fn(x) { x < 3 })`, false},
+		{`reduce(1..=100000, fn(sum, x) { sum + x })`, 5000050000},
+		{`let xs = [1, 2]; map(xs, fn(x) { push(xs, x); x })`, "[1, 2]"},
+		{`let h = {"a": 1}; map(h, fn(v) { h["b"] = 2; v })`, "[1]"},
+		{`flatten([1, [2, [3, [4]]], []])`, "[1, 2, 3, 4]"},
+		{`flatten([1, [2, [3, [4]]]], 1)`, "[1, 2, [3, [4]]]"},
+		{`flatten([[1], [2]], 0)`, "[[1], [2]]"},
+		{`let a = [1]; flatten([a, [a]])`, "[1, 1]"},
+		{`let a = [1]; push(a, a); flatten(a, 0)`, "[1, [1, [...]]]"},
+	}
+
+	testResults(t, NewEvaluator(), tests)
+}
+
+func TestCollectionBuiltinErrors(t *testing.T) {
+	tests := []errorTest{
+		{`map(1, fn(x) { x })`, "argument 1 to map must be ARRAY, HASH, STRING or RANGE, got INTEGER", 4},
+		{`map([1], 2)`, "argument 2 to map must be FUNCTION or BUILTIN, got INTEGER", 4},
+		{`map([1])`, "wrong number of arguments to map: want=2, got=1", 4},
+		{`map([1], fn(x, y) { x })`, "wrong number of arguments: want=2, got=1", 4},
+		{`map([1, "a"], fn(x) { x + 1 })`, "type mismatch: STRING + INTEGER", 25},
+		{`filter([1], fn(x) { -true })`, "unknown operator: -BOOLEAN", 21},
+		{`reduce([], fn(a, x) { a })`, "reduce of an empty ARRAY with no initial value", 7},
+		{`reduce([1], fn(a, x) { a }, 0, 1)`, "wrong number of arguments to reduce: want=2 to 3, got=4", 7},
+		{`sort([1, "a"])`, "type mismatch: STRING < INTEGER", 5},
+		{`sort([true, false])`, "unknown operator: BOOLEAN < BOOLEAN", 5},
+		{`sort([2, 1], fn(a, b) { "x" })`, "sort comparator must give BOOLEAN or INTEGER, got STRING", 5},
+		{`sort([2, 1], 1)`, "argument 2 to sort must be FUNCTION or BUILTIN, got INTEGER", 5},
+		{`sort_by("ab", len)`, "argument 1 to sort_by must be ARRAY, got STRING", 8},
+		{`zip()`, "wrong number of arguments to zip: want=at least 1, got=0", 4},
+		{`zip([1], 2)`, "argument 2 to zip must be ARRAY, HASH, STRING or RANGE, got INTEGER", 4},
+		{`flatten([1], -1)`, "negative flatten depth: -1", 8},
+		{`let a = [1]; push(a, a); flatten(a)`, "cannot flatten a cyclic ARRAY", 33},
+		{`let a = [1]; push(a, [a]); flatten([a])`, "cannot flatten a cyclic ARRAY", 35},
+		{`[1] |> any(fn(x) { y })`, "identifier not found: y", 20},
+	}
+
+	testErrors(t, tests)
+}
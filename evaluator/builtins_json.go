@@ -0,0 +1,257 @@
+package evaluator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"gibbon/object"
+	"gibbon/token"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxJSONIndent is the greatest number of spaces json_stringify indents by.
+const maxJSONIndent = 10
+
+// json_parse(s) is the value s holds in JSON: objects are read as hashes
+// keeping the order of their keys, numbers without a fraction or an exponent
+// as integers, and all other numbers as floats.
+func builtinJSONParse(at token.Token, arguments ...object.Object) object.Object {
+	strs, err := stringArguments(at, "json_parse", arguments, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(strs[0]))
+	decoder.UseNumber()
+
+	value, decodingErr := decodeJSONValue(decoder)
+	if decodingErr == nil {
+		if _, trailingErr := decoder.Token(); trailingErr != io.EOF {
+			decodingErr = errors.New("unexpected data after the top-level value")
+		}
+	}
+	if decodingErr != nil {
+		if decodingErr == io.EOF {
+			decodingErr = errors.New("unexpected end of JSON input")
+		}
+		return newError(at, "invalid JSON: %s", decodingErr)
+	}
+
+	return value
+}
+
+// json_stringify(value, indent?) is value written in JSON, on a single line
+// or indented with indent, a number of spaces or a string. Times are written
+// as strings, in RFC 3339.
+func builtinJSONStringify(at token.Token, arguments ...object.Object) object.Object {
+	if err := checkArity(at, "json_stringify", arguments, 1, 2); err != nil {
+		return err
+	}
+
+	indent := ""
+	if len(arguments) == 2 {
+		switch argument := arguments[1].(type) {
+		case *object.Integer:
+			if argument.Value < 0 || argument.Value > maxJSONIndent {
+				return newError(at, "json_stringify indent out of range: %d", argument.Value)
+			}
+			indent = strings.Repeat(" ", int(argument.Value))
+		case *object.String:
+			indent = argument.Value
+		default:
+			return argumentTypeError(at, "json_stringify", 2, argument, object.INTEGER_OBJ, object.STRING_OBJ)
+		}
+	}
+
+	encoder := &jsonEncoder{at: at, visiting: map[object.Object]bool{}}
+	if err := encoder.encode(arguments[0]); err != nil {
+		return err
+	}
+
+	if indent == "" {
+		return &object.String{Value: encoder.output.String()}
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, encoder.output.Bytes(), "", indent); err != nil {
+		return newError(at, "cannot indent JSON: %s", err)
+	}
+	return &object.String{Value: indented.String()}
+}
+
+// ============ decoding ============
+
+// decodeJSONValue reads the next JSON value from decoder.
+func decodeJSONValue(decoder *json.Decoder) (object.Object, error) {
+	tok, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok := tok.(type) {
+	case json.Delim:
+		if tok == '[' {
+			return decodeJSONArray(decoder)
+		}
+		return decodeJSONObject(decoder)
+	case string:
+		return &object.String{Value: tok}, nil
+	case json.Number:
+		return decodeJSONNumber(tok)
+	case bool:
+		return nativeBoolToBooleanObject(tok), nil
+	}
+
+	return NULL, nil
+}
+
+func decodeJSONArray(decoder *json.Decoder) (object.Object, error) {
+	elements := []object.Object{}
+	for decoder.More() {
+		element, err := decodeJSONValue(decoder)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+	}
+
+	// the closing bracket
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+
+	return &object.Array{Elements: elements}, nil
+}
+
+func decodeJSONObject(decoder *json.Decoder) (object.Object, error) {
+	hash := object.NewHash()
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := decodeJSONValue(decoder)
+		if err != nil {
+			return nil, err
+		}
+		hash.Set(&object.String{Value: key.(string)}, value)
+	}
+
+	// the closing brace
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+
+	return hash, nil
+}
+
+func decodeJSONNumber(number json.Number) (object.Object, error) {
+	literal := number.String()
+	if !strings.ContainsAny(literal, ".eE") {
+		if integer, ok := new(big.Int).SetString(literal, 10); ok {
+			return bigIntegerObject(integer), nil
+		}
+	}
+
+	value, err := strconv.ParseFloat(literal, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &object.Float{Value: value}, nil
+}
+
+// ============ encoding ============
+
+// jsonEncoder writes objects as compact JSON, reporting errors at the call
+// site at.
+type jsonEncoder struct {
+	at       token.Token
+	output   bytes.Buffer
+	visiting map[object.Object]bool // the arrays and hashes being written, to detect cycles
+}
+
+func (je *jsonEncoder) encode(value object.Object) *object.Error {
+	switch value := value.(type) {
+	case *object.Null:
+		je.output.WriteString("null")
+	case *object.Boolean:
+		je.output.WriteString(strconv.FormatBool(value.Value))
+	case *object.Integer, *object.BigInteger, *object.Decimal:
+		je.output.WriteString(value.Inspect())
+	case *object.Float:
+		if math.IsNaN(value.Value) || math.IsInf(value.Value, 0) {
+			return newError(je.at, "cannot write %s in JSON", value.Inspect())
+		}
+		je.output.WriteString(strconv.FormatFloat(value.Value, 'g', -1, 64))
+	case *object.String:
+		je.encodeString(value.Value)
+	case *object.Time:
+		je.encodeString(value.Inspect())
+	case *object.Array:
+		return je.encodeContainer(value, '[', ']', len(value.Elements), func(i int) *object.Error {
+			return je.encode(value.Elements[i])
+		})
+	case *object.Hash:
+		return je.encodeContainer(value, '{', '}', len(value.Keys), func(i int) *object.Error {
+			pair := value.Pairs[value.Keys[i]]
+			switch key := pair.Key.(type) {
+			case *object.String:
+				je.encodeString(key.Value)
+			case *object.Integer, *object.BigInteger:
+				je.encodeString(key.Inspect())
+			default:
+				return newError(je.at, "cannot write a %s hash key in JSON", key.Type())
+			}
+			je.output.WriteByte(':')
+			return je.encode(pair.Value)
+		})
+	default:
+		return newError(je.at, "cannot write %s in JSON", value.Type())
+	}
+
+	return nil
+}
+
+// encodeContainer writes the count items of container, an array or a hash,
+// between opening and closing, using encodeItem.
+func (je *jsonEncoder) encodeContainer(
+	container object.Object,
+	opening, closing byte,
+	count int,
+	encodeItem func(i int) *object.Error,
+) *object.Error {
+	if je.visiting[container] {
+		return newError(je.at, "cannot write a cyclic %s in JSON", container.Type())
+	}
+	je.visiting[container] = true
+	defer delete(je.visiting, container)
+
+	je.output.WriteByte(opening)
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			je.output.WriteByte(',')
+		}
+		if err := encodeItem(i); err != nil {
+			return err
+		}
+	}
+	je.output.WriteByte(closing)
+
+	return nil
+}
+
+// encodeString writes str as a JSON string, leaving "<", ">" and "&" as they
+// are rather than escaping them for HTML.
+func (je *jsonEncoder) encodeString(str string) {
+	encoder := json.NewEncoder(&je.output)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(str)
+
+	// Encode ends values with a newline
+	je.output.Truncate(je.output.Len() - 1)
+}
@@ -0,0 +1,55 @@
+package evaluator
+
+import "testing"
+
+func TestJSONBuiltins(t *testing.T) {
+	tests := []resultTest{
+		{`json_parse("42")`, 42},
+		{`json_parse("-7")`, -7},
+		{`json_parse("true")`, true},
+		{`json_parse("\"h\\u00e9\"")`, "hé"},
+		{`json_parse("null")`, "null"},
+		{`json_parse("1.5")`, "1.5"},
+		{`json_parse("1e3")`, "1000.0"},
+		{`json_parse("123456789012345678901234567890")`, "123456789012345678901234567890"},
+		{`json_parse(" [1, \"a\", [], {}] ")`, "[1, a, [], {}]"},
+		{`json_parse("{\"b\": 1, \"a\": [true, null]}")`, "{b: 1, a: [true, null]}"},
+		{`json_parse("{\"a\": 1, \"b\": 2, \"a\": 3}")`, "{a: 3, b: 2}"},
+		{`json_parse("{\"k\": {\"n\": 1}}")["k"]["n"]`, 1},
+		{`json_stringify(null)`, "null"},
+		{`json_stringify([1, -2.5, 1.50d, true, "x"])`, `[1,-2.5,1.50,true,"x"]`},
+		{`json_stringify(1 << 70)`, "1180591620717411303424"},
+		{`json_stringify("a\"b\\c\n<&>é")`, `"a\"b\\c\n<&>é"`},
+		{`json_stringify({"b": 1, "a": {}, 3: []})`, `{"b":1,"a":{},"3":[]}`},
+		{`json_stringify([1, [2]], 2)`, "[\n  1,\n  [\n    2\n  ]\n]"},
+		{`json_stringify({"a": 1}, "\t")`, "{\n\t\"a\": 1\n}"},
+		{`json_stringify([], 4)`, "[]"},
+		{`json_stringify({"at": time_parse("2024-05-17T10:30:00+02:00")})`, `{"at":"2024-05-17T10:30:00+02:00"}`},
+		{`let a = [1]; json_stringify([a, a])`, "[[1],[1]]"},
+		{`let s = "{\"x\":[1,2.5,\"y\"],\"z\":null}"; json_stringify(json_parse(s)) == s`, true},
+	}
+
+	testResults(t, NewEvaluator(), tests)
+}
+
+func TestJSONBuiltinErrors(t *testing.T) {
+	tests := []errorTest{
+		{`json_parse(1)`, "argument 1 to json_parse must be STRING, got INTEGER", 11},
+		{`json_parse("")`, "invalid JSON: unexpected end of JSON input", 11},
+		{`json_parse("[1,")`, "invalid JSON: unexpected end of JSON input", 11},
+		{`json_parse("[1 2]")`, "invalid JSON: invalid character '2' after array element", 11},
+		{`json_parse("{1: 2}")`, "invalid JSON: object member name must be a string", 11},
+		{`json_parse("1 2")`, "invalid JSON: unexpected data after the top-level value", 11},
+		{`json_stringify()`, "wrong number of arguments to json_stringify: want=1 to 2, got=0", 15},
+		{`json_stringify(fn(x) { x })`, "cannot write FUNCTION in JSON", 15},
+		{`json_stringify([1, len])`, "cannot write BUILTIN in JSON", 15},
+		{`json_stringify({"f": 1..3})`, "cannot write RANGE in JSON", 15},
+		{`json_stringify([exp(1000)])`, "cannot write +Inf in JSON", 15},
+		{`json_stringify({true: 1})`, "cannot write a BOOLEAN hash key in JSON", 15},
+		{`let a = [1]; push(a, a); json_stringify(a)`, "cannot write a cyclic ARRAY in JSON", 40},
+		{`json_stringify(1, 11)`, "json_stringify indent out of range: 11", 15},
+		{`json_stringify(1, true)`, "argument 2 to json_stringify must be INTEGER or STRING, got BOOLEAN", 15},
+	}
+
+	testErrors(t, tests)
+}
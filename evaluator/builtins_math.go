@@ -0,0 +1,339 @@
+package evaluator
+
+import (
+	"gibbon/object"
+	"gibbon/token"
+	"math"
+	"math/big"
+)
+
+// Math builtins take any number. Those that are exact, such as abs, floor or
+// min, give back numbers of the kind they were given, whereas the others
+// always give a float. Arguments outside a function's domain are errors
+// rather than NaN results.
+
+// numericTypes are the types of the objects math builtins accept.
+var numericTypes = []object.ObjectType{
+	object.INTEGER_OBJ, object.BIG_INTEGER_OBJ, object.FLOAT_OBJ, object.RATIONAL_OBJ, object.DECIMAL_OBJ,
+}
+
+// abs(x) is the absolute value of x.
+func (e *Evaluator) builtinAbs(at token.Token, arguments ...object.Object) object.Object {
+	x, err := numberArgument(at, "abs", arguments)
+	if err != nil {
+		return err
+	}
+
+	switch x := x.(type) {
+	case *object.Integer:
+		if x.Value >= 0 {
+			return x
+		}
+		if x.Value == math.MinInt64 {
+			if e.detectOverflow {
+				return newError(at, "integer overflow: abs(%d)", x.Value)
+			}
+			return bigIntegerObject(new(big.Int).Neg(big.NewInt(x.Value)))
+		}
+		return &object.Integer{Value: -x.Value}
+	case *object.BigInteger:
+		return &object.BigInteger{Value: new(big.Int).Abs(x.Value)}
+	case *object.Float:
+		return &object.Float{Value: math.Abs(x.Value)}
+	case *object.Rational:
+		return &object.Rational{Value: new(big.Rat).Abs(x.Value)}
+	case *object.Decimal:
+		return &object.Decimal{Unscaled: new(big.Int).Abs(x.Unscaled), Scale: x.Scale}
+	}
+
+	return x
+}
+
+// min(values...) is the least of the values, which may also be given as a
+// single array.
+func (e *Evaluator) builtinMin(at token.Token, arguments ...object.Object) object.Object {
+	return e.findExtremum(at, "min", arguments, false)
+}
+
+// max(values...) is the greatest of the values, which may also be given as a
+// single array.
+func (e *Evaluator) builtinMax(at token.Token, arguments ...object.Object) object.Object {
+	return e.findExtremum(at, "max", arguments, true)
+}
+
+// pow(base, exponent) is base ** exponent, except that a negative integer
+// exponent gives a float rather than an error.
+func (e *Evaluator) builtinPow(at token.Token, arguments ...object.Object) object.Object {
+	if err := checkArity(at, "pow", arguments, 2, 2); err != nil {
+		return err
+	}
+
+	if err := checkNumbers(at, "pow", arguments); err != nil {
+		return err
+	}
+
+	base, exponent := arguments[0], arguments[1]
+	negativeIntegerExponent := isInteger(exponent) && toBigInt(exponent).Sign() < 0
+	if base.Type() != object.FLOAT_OBJ && exponent.Type() != object.FLOAT_OBJ &&
+		!(isInteger(base) && negativeIntegerExponent) {
+		operator := token.Token{Type: token.POWER, Literal: token.POWER.Lexeme(), Location: at.Location}
+		return e.evalInfixOperation(operator, base, exponent)
+	}
+
+	return floatPower(at, base, exponent)
+}
+
+// sqrt(x) is the square root of x.
+func builtinSqrt(at token.Token, arguments ...object.Object) object.Object {
+	return floatFunction("sqrt", math.Sqrt, nonNegative)(at, arguments...)
+}
+
+// floor(x) is the greatest integer less than or equal to x.
+func builtinFloor(at token.Token, arguments ...object.Object) object.Object {
+	return roundToInteger(at, "floor", arguments, math.Floor, object.RoundFloor)
+}
+
+// ceil(x) is the least integer greater than or equal to x.
+func builtinCeil(at token.Token, arguments ...object.Object) object.Object {
+	return roundToInteger(at, "ceil", arguments, math.Ceil, object.RoundCeiling)
+}
+
+// round(x, digits?) is x rounded to an integer, or to digits fractional
+// digits. Floats round half away from zero, while decimals and rationals
+// follow the rounding mode of the evaluator and become decimals when rounded
+// to digits.
+func (e *Evaluator) builtinRound(at token.Token, arguments ...object.Object) object.Object {
+	if err := checkArity(at, "round", arguments, 1, 2); err != nil {
+		return err
+	}
+	if len(arguments) == 1 {
+		return roundToInteger(at, "round", arguments, math.Round, e.roundingMode)
+	}
+
+	x := arguments[0]
+	if !isNumeric(x) {
+		return argumentTypeError(at, "round", 1, x, numericTypes...)
+	}
+	digits, err := integerArgument(at, "round", 2, arguments[1])
+	if err != nil {
+		return err
+	}
+	if digits < 0 {
+		return newError(at, "negative number of digits to round to: %d", digits)
+	}
+	if digits > maxDecimalPrecision {
+		return newError(at, "too many digits to round to: %d", digits)
+	}
+
+	switch x := x.(type) {
+	case *object.Float:
+		scale := math.Pow(10, float64(digits))
+		if math.IsInf(x.Value*scale, 0) {
+			return x
+		}
+		return &object.Float{Value: math.Round(x.Value*scale) / scale}
+	case *object.Rational, *object.Decimal:
+		return object.NewDecimal(toRat(x), digits, e.roundingMode)
+	}
+
+	return x
+}
+
+// clamp(x, low, high) is x, or the nearest bound when it is out of
+// low..=high.
+func (e *Evaluator) builtinClamp(at token.Token, arguments ...object.Object) object.Object {
+	if err := checkArity(at, "clamp", arguments, 3, 3); err != nil {
+		return err
+	}
+
+	x, low, high := arguments[0], arguments[1], arguments[2]
+
+	outOfOrder := e.evalLess(at, high, low)
+	if interrupts(outOfOrder) {
+		return outOfOrder
+	}
+	if outOfOrder == TRUE {
+		return newError(at, "clamp bounds out of order: %s > %s", low.Inspect(), high.Inspect())
+	}
+
+	below := e.evalLess(at, x, low)
+	if interrupts(below) {
+		return below
+	}
+	if below == TRUE {
+		return low
+	}
+
+	above := e.evalLess(at, high, x)
+	if interrupts(above) {
+		return above
+	}
+	if above == TRUE {
+		return high
+	}
+
+	return x
+}
+
+// atan2(y, x) is the angle of the point (x, y) from the x axis, in radians.
+func builtinAtan2(at token.Token, arguments ...object.Object) object.Object {
+	if err := checkArity(at, "atan2", arguments, 2, 2); err != nil {
+		return err
+	}
+
+	if err := checkNumbers(at, "atan2", arguments); err != nil {
+		return err
+	}
+
+	return &object.Float{Value: math.Atan2(toFloat(arguments[0]), toFloat(arguments[1]))}
+}
+
+// log(x, base?) is the natural logarithm of x, or its logarithm in base.
+func builtinLog(at token.Token, arguments ...object.Object) object.Object {
+	if err := checkArity(at, "log", arguments, 1, 2); err != nil {
+		return err
+	}
+	if len(arguments) == 1 {
+		return floatFunction("log", math.Log, positive)(at, arguments...)
+	}
+
+	if err := checkNumbers(at, "log", arguments); err != nil {
+		return err
+	}
+
+	x, base := toFloat(arguments[0]), toFloat(arguments[1])
+	if !positive.contains(x) {
+		return newError(at, "log argument is %s: %s", positive.description, arguments[0].Inspect())
+	}
+	if !positive.contains(base) || base == 1 {
+		return newError(at, "log base is not positive or is 1: %s", arguments[1].Inspect())
+	}
+
+	return &object.Float{Value: math.Log(x) / math.Log(base)}
+}
+
+// ============ helpers ============
+
+// domain is the set of arguments a float function is defined for.
+type domain struct {
+	contains    func(x float64) bool
+	description string // how an argument out of the domain is, such as "negative"
+}
+
+var (
+	nonNegative     = &domain{func(x float64) bool { return x >= 0 }, "negative"}
+	positive        = &domain{func(x float64) bool { return x > 0 }, "not positive"}
+	withinUnitRange = &domain{func(x float64) bool { return -1 <= x && x <= 1 }, "outside [-1, 1]"}
+)
+
+// floatFunction makes a builtin of fn, which takes and gives a float, its
+// argument being checked to be in valid unless valid is nil.
+func floatFunction(name string, fn func(float64) float64, valid *domain) object.BuiltinFunction {
+	return func(at token.Token, arguments ...object.Object) object.Object {
+		argument, err := numberArgument(at, name, arguments)
+		if err != nil {
+			return err
+		}
+
+		x := toFloat(argument)
+		if valid != nil && !valid.contains(x) {
+			return newError(at, "%s argument is %s: %s", name, valid.description, argument.Inspect())
+		}
+
+		return &object.Float{Value: fn(x)}
+	}
+}
+
+// numberArgument returns the only argument of the builtin name, which must
+// be a number.
+func numberArgument(at token.Token, name string, arguments []object.Object) (object.Object, *object.Error) {
+	if err := checkArity(at, name, arguments, 1, 1); err != nil {
+		return nil, err
+	}
+
+	if !isNumeric(arguments[0]) {
+		return nil, argumentTypeError(at, name, 1, arguments[0], numericTypes...)
+	}
+
+	return arguments[0], nil
+}
+
+// roundToInteger rounds the only argument of the builtin name to an integer,
+// with roundFloat for floats and mode for decimals and rationals.
+func roundToInteger(
+	at token.Token,
+	name string,
+	arguments []object.Object,
+	roundFloat func(float64) float64,
+	mode object.RoundingMode,
+) object.Object {
+	x, err := numberArgument(at, name, arguments)
+	if err != nil {
+		return err
+	}
+
+	switch x := x.(type) {
+	case *object.Float:
+		rounded := roundFloat(x.Value)
+		if math.IsNaN(rounded) || math.IsInf(rounded, 0) {
+			return newError(at, "cannot %s %s to an integer", name, x.Inspect())
+		}
+		integer, _ := new(big.Float).SetFloat64(rounded).Int(nil)
+		return bigIntegerObject(integer)
+	case *object.Rational, *object.Decimal:
+		return bigIntegerObject(object.NewDecimal(toRat(x), 0, mode).Unscaled)
+	}
+
+	return x
+}
+
+// findExtremum returns the greatest of the values given to the builtin name
+// when greatest is set, or the least otherwise, the first one of equal
+// values.
+func (e *Evaluator) findExtremum(at token.Token, name string, arguments []object.Object, greatest bool) object.Object {
+	if err := checkArity(at, name, arguments, 1, -1); err != nil {
+		return err
+	}
+
+	values := arguments
+	if array, ok := arguments[0].(*object.Array); ok && len(arguments) == 1 {
+		if len(array.Elements) == 0 {
+			return newError(at, "%s of an empty ARRAY", name)
+		}
+		values = array.Elements
+	}
+
+	extremum := values[0]
+	for _, value := range values[1:] {
+		a, b := value, extremum
+		if greatest {
+			a, b = b, a
+		}
+
+		beyond := e.evalLess(at, a, b)
+		if interrupts(beyond) {
+			return beyond
+		}
+		if beyond == TRUE {
+			extremum = value
+		}
+	}
+
+	return extremum
+}
+
+// checkNumbers returns an error unless every argument to the builtin name is
+// a number.
+func checkNumbers(at token.Token, name string, arguments []object.Object) *object.Error {
+	for i, argument := range arguments {
+		if !isNumeric(argument) {
+			return argumentTypeError(at, name, i+1, argument, numericTypes...)
+		}
+	}
+
+	return nil
+}
+
+func isNumeric(obj object.Object) bool {
+	return isNumber(obj) || obj.Type() == object.FLOAT_OBJ
+}
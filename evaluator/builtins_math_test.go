@@ -0,0 +1,104 @@
+package evaluator
+
+import "testing"
+
+func TestMathBuiltins(t *testing.T) {
+	tests := []resultTest{
+		{`PI`, "3.141592653589793"},
+		{`E`, "2.718281828459045"},
+		{`abs(-5)`, 5},
+		{`abs(5)`, 5},
+		{`abs(-9223372036854775807 - 1)`, "9223372036854775808"},
+		{`abs(-(1 << 70))`, "1180591620717411303424"},
+		{`abs(-2.5)`, "2.5"},
+		{`abs(-12.50d)`, "12.50"},
+		{`min(3, 1, 2)`, 1},
+		{`max(3, 1, 2)`, 3},
+		{`min([4, 2, 8])`, 2},
+		{`max([4])`, 4},
+		{`max(1, 2.5)`, "2.5"},
+		{`min(1.5d, 2, 0.5d)`, "0.5"},
+		{`max("b", "c", "a")`, "c"},
+		{`min(7)`, 7},
+		{`pow(2, 10)`, 1024},
+		{`pow(2, 64)`, "18446744073709551616"},
+		{`pow(2, -2)`, "0.25"},
+		{`pow(4, 0.5)`, "2.0"},
+		{`pow(-8.0, 3)`, "-512.0"},
+		{`pow(1.5d, 2)`, "2.25"},
+		{`sqrt(16)`, "4.0"},
+		{`sqrt(2.25)`, "1.5"},
+		{`sqrt(0)`, "0.0"},
+		{`floor(2.7)`, 2},
+		{`floor(-2.5)`, -3},
+		{`floor(7)`, 7},
+		{`floor(-7.5d)`, -8},
+		{`ceil(2.1)`, 3},
+		{`ceil(-2.5)`, -2},
+		{`ceil(1.01d)`, 2},
+		{`floor(2.0 ** 70)`, "1180591620717411303424"},
+		{`round(2.5)`, 3},
+		{`round(-2.5)`, -3},
+		{`round(2.4)`, 2},
+		{`round(2.5d)`, 2},
+		{`round(3.5d)`, 4},
+		{`round(3.14159, 2)`, "3.14"},
+		{`round(2.675d, 2)`, "2.68"},
+		{`round(2.665d, 2)`, "2.66"},
+		{`round(12, 2)`, 12},
+		{`clamp(5, 1, 10)`, 5},
+		{`clamp(-5, 1, 10)`, 1},
+		{`clamp(50, 1, 10)`, 10},
+		{`clamp(0.5, 0, 1)`, "0.5"},
+		{`sin(0)`, "0.0"},
+		{`cos(0)`, "1.0"},
+		{`round(sin(PI / 2), 6)`, "1.0"},
+		{`round(tan(PI / 4), 6)`, "1.0"},
+		{`asin(1) == PI / 2`, true},
+		{`acos(1)`, "0.0"},
+		{`atan(0)`, "0.0"},
+		{`atan2(1, 1) == PI / 4`, true},
+		{`exp(0)`, "1.0"},
+		{`log(E)`, "1.0"},
+		{`log(8, 2)`, "3.0"},
+		{`log2(1024)`, "10.0"},
+		{`log10(1000)`, "3.0"},
+		{`[1, 4, 9] |> map(sqrt)`, "[1.0, 2.0, 3.0]"},
+	}
+
+	testResults(t, NewEvaluator(), tests)
+}
+
+func TestMathBuiltinErrors(t *testing.T) {
+	tests := []errorTest{
+		{`sqrt(-4)`, "sqrt argument is negative: -4", 5},
+		{`sqrt(-0.5)`, "sqrt argument is negative: -0.5", 5},
+		{`log(0)`, "log argument is not positive: 0", 4},
+		{`log(-1.0, 2)`, "log argument is not positive: -1.0", 4},
+		{`log(8, 1)`, "log base is not positive or is 1: 1", 4},
+		{`log2(-2)`, "log2 argument is not positive: -2", 5},
+		{`log10(0)`, "log10 argument is not positive: 0", 6},
+		{`asin(2)`, "asin argument is outside [-1, 1]: 2", 5},
+		{`acos(-1.5)`, "acos argument is outside [-1, 1]: -1.5", 5},
+		{`pow(-8, 0.5)`, "fractional power of a negative number: -8 ** 0.5", 4},
+		{`pow(10.0, 400)`, "number too large: 10.0 ** 400", 4},
+		{`pow(0, -1)`, "division by zero", 4},
+		{`pow(2, 1 << 70)`, "integer too large: 2 ** 1180591620717411303424", 4},
+		{`sin("x")`, "argument 1 to sin must be INTEGER, BIG_INTEGER, FLOAT, RATIONAL or DECIMAL, got STRING", 4},
+		{`abs()`, "wrong number of arguments to abs: want=1, got=0", 4},
+		{`min()`, "wrong number of arguments to min: want=at least 1, got=0", 4},
+		{`max([])`, "max of an empty ARRAY", 4},
+		{`min(1, "a")`, "type mismatch: STRING < INTEGER", 4},
+		{`clamp(5, 10, 1)`, "clamp bounds out of order: 10 > 1", 6},
+		{`round(1.5, -1)`, "negative number of digits to round to: -1", 6},
+		{`round(1.5d, 2000000000)`, "too many digits to round to: 2000000000", 6},
+		{`floor(exp(1000))`, "cannot floor +Inf to an integer", 6},
+	}
+
+	testErrors(t, tests)
+
+	e := NewEvaluator()
+	e.DetectOverflow()
+	testErrorObject(t, testEvalWith(t, e, `abs(-9223372036854775807 - 1)`),
+		"integer overflow: abs(-9223372036854775808)", 1, 4)
+}
@@ -0,0 +1,222 @@
+package evaluator
+
+import (
+	"gibbon/object"
+	"gibbon/token"
+	"regexp"
+	"strings"
+)
+
+// Regex builtins take patterns in the syntax of Go's regexp package, either as
+// strings, compiled the first time they are used, or as regexes re_compile
+// made. A match is an array of the text it covers followed by its groups, null
+// for those that did not take part in it, or a hash when the pattern names
+// some of its groups, keyed by the indexes of the groups as well as by their
+// names.
+
+// maxCachedRegexes is the number of compiled patterns an evaluator keeps. The
+// cache is emptied when it is full.
+const maxCachedRegexes = 256
+
+// regexTypes are the types of the patterns regex builtins accept.
+var regexTypes = []object.ObjectType{object.STRING_OBJ, object.REGEX_OBJ}
+
+// re_compile(pattern) is pattern compiled into a regex.
+func (e *Evaluator) builtinReCompile(at token.Token, arguments ...object.Object) object.Object {
+	strs, err := stringArguments(at, "re_compile", arguments, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	regex, err := e.compileRegex(at, strs[0])
+	if err != nil {
+		return err
+	}
+
+	return regex
+}
+
+// re_match(s, pattern) is the first match of pattern in s, or null if there
+// is none.
+func (e *Evaluator) builtinReMatch(at token.Token, arguments ...object.Object) object.Object {
+	str, re, err := e.regexArguments(at, "re_match", arguments, 2, 2)
+	if err != nil {
+		return err
+	}
+
+	match := re.FindStringSubmatchIndex(str)
+	if match == nil {
+		return NULL
+	}
+
+	return matchObject(re, str, match)
+}
+
+// re_find_all(s, pattern) is an array of the matches of pattern in s, which
+// are only the text they cover when pattern has no groups.
+func (e *Evaluator) builtinReFindAll(at token.Token, arguments ...object.Object) object.Object {
+	str, re, err := e.regexArguments(at, "re_find_all", arguments, 2, 2)
+	if err != nil {
+		return err
+	}
+
+	if re.NumSubexp() == 0 {
+		return stringArray(re.FindAllString(str, -1))
+	}
+
+	matches := re.FindAllStringSubmatchIndex(str, -1)
+	elements := make([]object.Object, len(matches))
+	for i, match := range matches {
+		elements[i] = matchObject(re, str, match)
+	}
+
+	return &object.Array{Elements: elements}
+}
+
+// re_replace(s, pattern, replacement) is s with every match of pattern
+// replaced. A string replacement may refer to groups as $1 or ${name}, and a
+// function replacement is given each match and must give a string.
+func (e *Evaluator) builtinReReplace(at token.Token, arguments ...object.Object) object.Object {
+	str, re, err := e.regexArguments(at, "re_replace", arguments, 3, 3)
+	if err != nil {
+		return err
+	}
+
+	switch replacement := arguments[2].(type) {
+	case *object.String:
+		return &object.String{Value: re.ReplaceAllString(str, replacement.Value)}
+	case *object.Function, *object.Builtin:
+		var replaced strings.Builder
+		end := 0
+		for _, match := range re.FindAllStringSubmatchIndex(str, -1) {
+			result := e.applyFunction(at, replacement, []object.Object{matchObject(re, str, match)})
+			if interrupts(result) {
+				return result
+			}
+			resultString, ok := result.(*object.String)
+			if !ok {
+				return newError(at, "re_replace replacement must give %s, got %s", object.STRING_OBJ, result.Type())
+			}
+
+			replaced.WriteString(str[end:match[0]])
+			replaced.WriteString(resultString.Value)
+			end = match[1]
+		}
+		replaced.WriteString(str[end:])
+
+		return &object.String{Value: replaced.String()}
+	}
+
+	return argumentTypeError(at, "re_replace", 3, arguments[2],
+		object.STRING_OBJ, object.FUNCTION_OBJ, object.BUILTIN_OBJ)
+}
+
+// re_split(s, pattern, limit?) is an array of the parts of s between matches
+// of pattern, at most limit of them, the last holding the rest of s.
+func (e *Evaluator) builtinReSplit(at token.Token, arguments ...object.Object) object.Object {
+	str, re, err := e.regexArguments(at, "re_split", arguments, 2, 3)
+	if err != nil {
+		return err
+	}
+
+	limit := -1
+	if len(arguments) == 3 {
+		if limit, err = integerArgument(at, "re_split", 3, arguments[2]); err != nil {
+			return err
+		}
+		if limit <= 0 {
+			return newError(at, "non-positive re_split limit: %d", limit)
+		}
+	}
+
+	return stringArray(re.Split(str, limit))
+}
+
+// ============ helpers ============
+
+// regexArguments checks that between minimum and maximum arguments are given
+// to the builtin name, the first being a string and the second a pattern, and
+// returns them.
+func (e *Evaluator) regexArguments(
+	at token.Token,
+	name string,
+	arguments []object.Object,
+	minimum, maximum int,
+) (string, *regexp.Regexp, *object.Error) {
+	if err := checkArity(at, name, arguments, minimum, maximum); err != nil {
+		return "", nil, err
+	}
+
+	str, ok := arguments[0].(*object.String)
+	if !ok {
+		return "", nil, argumentTypeError(at, name, 1, arguments[0], object.STRING_OBJ)
+	}
+
+	switch pattern := arguments[1].(type) {
+	case *object.String:
+		regex, err := e.compileRegex(at, pattern.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		return str.Value, regex.Value, nil
+	case *object.Regex:
+		return str.Value, pattern.Value, nil
+	}
+
+	return "", nil, argumentTypeError(at, name, 2, arguments[1], regexTypes...)
+}
+
+// compileRegex returns the regex of pattern, compiling it unless the
+// evaluator already has.
+func (e *Evaluator) compileRegex(at token.Token, pattern string) (*object.Regex, *object.Error) {
+	if regex, ok := e.regexes[pattern]; ok {
+		return regex, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, newError(at, "invalid regular expression: %s",
+			strings.TrimPrefix(err.Error(), "error parsing regexp: "))
+	}
+
+	if len(e.regexes) >= maxCachedRegexes {
+		e.regexes = map[string]*object.Regex{}
+	}
+	regex := &object.Regex{Value: re}
+	e.regexes[pattern] = regex
+
+	return regex, nil
+}
+
+// matchObject returns the match of re in str at indexes, as given by the
+// Submatch methods of regexp.
+func matchObject(re *regexp.Regexp, str string, indexes []int) object.Object {
+	groups := make([]object.Object, len(indexes)/2)
+	for i := range groups {
+		start, end := indexes[2*i], indexes[2*i+1]
+		if start < 0 {
+			groups[i] = NULL
+		} else {
+			groups[i] = &object.String{Value: str[start:end]}
+		}
+	}
+
+	names := re.SubexpNames()
+	named := false
+	for _, name := range names {
+		named = named || name != ""
+	}
+	if !named {
+		return &object.Array{Elements: groups}
+	}
+
+	hash := object.NewHash()
+	for i, group := range groups {
+		hash.Set(&object.Integer{Value: int64(i)}, group)
+		if names[i] != "" {
+			hash.Set(&object.String{Value: names[i]}, group)
+		}
+	}
+
+	return hash
+}
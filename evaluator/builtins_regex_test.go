@@ -0,0 +1,72 @@
+package evaluator
+
+import (
+	"gibbon/token"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegexBuiltins(t *testing.T) {
+	tests := []resultTest{
+		{`re_match("abc123", "\\d+")`, "[123]"},
+		{`re_match("2024-05-17", "(\\d+)-(\\d+)")`, "[2024-05, 2024, 05]"},
+		{`re_match("abc", "\\d")`, "null"},
+		{`re_match("ac", "a(b)?c")`, "[ac, null]"},
+		{`re_match("key=val", "(?P<k>\\w+)=(?P<v>\\w+)")`, "{0: key=val, 1: key, k: key, 2: val, v: val}"},
+		{`re_match("key=val", "(?P<k>\\w+)=(\\w+)")["k"]`, "key"},
+		{`re_match("日本語", "本.")`, "[本語]"},
+		{`re_find_all("a1b22c333", "\\d+")`, "[1, 22, 333]"},
+		{`re_find_all("abc", "\\d")`, "[]"},
+		{`re_find_all("a=1, b=2", "(\\w)=(\\d)")`, "[[a=1, a, 1], [b=2, b, 2]]"},
+		{`re_replace("a1b22", "\\d+", "#")`, "a#b#"},
+		{`re_replace("john smith", "(\\w+) (\\w+)", "$2, $1")`, "smith, john"},
+		{`re_replace("x=1", "(?P<k>\\w)=(?P<v>\\d)", "\${v}=\${k}")`, "1=x"},
+		{`re_replace("a1b22", "\\d+", fn(m) { str(len(m[0])) })`, "a1b2"},
+		{`re_replace("hello world", "\\w+", fn(m) { upper(m[0]) })`, "HELLO WORLD"},
+		{`re_split("a, b;c", "[,;] ?")`, "[a, b, c]"},
+		{`re_split("a1b2c3d", "\\d", 2)`, "[a, b2c3d]"},
+		{`re_split("", ",")`, "[]"},
+		{`re_compile("a+b")`, "/a+b/"},
+		{`type(re_compile("a"))`, "REGEX"},
+		{`let digits = re_compile("\\d+"); re_find_all("1 23", digits)`, "[1, 23]"},
+		{`"a-b_c" |> re_split("[-_]") |> join("")`, "abc"},
+	}
+
+	testResults(t, NewEvaluator(), tests)
+}
+
+func TestRegexCache(t *testing.T) {
+	assert := assert.New(t)
+
+	e := NewEvaluator()
+	testEvalWith(t, e, `re_match("a", "a+"); re_find_all("aa", "a+")`)
+	assert.Len(e.regexes, 1)
+
+	regex := e.regexes["a+"]
+	assert.Same(regex, testEvalWith(t, e, `re_compile("a+")`))
+
+	for i := 0; i < maxCachedRegexes; i++ {
+		e.compileRegex(token.Token{}, strings.Repeat("a", i+2))
+	}
+	assert.LessOrEqual(len(e.regexes), maxCachedRegexes)
+}
+
+func TestRegexBuiltinErrors(t *testing.T) {
+	tests := []errorTest{
+		{`re_match("a", "(a")`, "invalid regular expression: missing closing ): `(a`", 9},
+		{`re_find_all("a", "a**")`, "invalid regular expression: invalid nested repetition operator: `**`", 12},
+		{`let p = "[z-a]"; re_split("a", p)`, "invalid regular expression: invalid character class range: `z-a`", 26},
+		{`re_compile("\\")`, "invalid regular expression: trailing backslash at end of expression: ``", 11},
+		{`re_match("a")`, "wrong number of arguments to re_match: want=2, got=1", 9},
+		{`re_match(1, "a")`, "argument 1 to re_match must be STRING, got INTEGER", 9},
+		{`re_match("a", 1)`, "argument 2 to re_match must be STRING or REGEX, got INTEGER", 9},
+		{`re_replace("a", "a", 1)`, "argument 3 to re_replace must be STRING, FUNCTION or BUILTIN, got INTEGER", 11},
+		{`re_replace("a", "a", fn(m) { 1 })`, "re_replace replacement must give STRING, got INTEGER", 11},
+		{`re_replace("a", "a", fn(m) { m + 1 })`, "type mismatch: ARRAY + INTEGER", 32},
+		{`re_split("a", "b", 0)`, "non-positive re_split limit: 0", 9},
+	}
+
+	testErrors(t, tests)
+}
@@ -0,0 +1,327 @@
+package evaluator
+
+import (
+	"gibbon/object"
+	"gibbon/token"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// String builtins count and index characters, that is Unicode code points,
+// rather than bytes, as len does.
+
+// maxStringLength is the longest string, in bytes, the string builtins build.
+const maxStringLength = 1 << 28
+
+// split(s, separator) is an array of the parts of s between separators, or
+// of its characters for an empty separator.
+func builtinSplit(at token.Token, arguments ...object.Object) object.Object {
+	strs, err := stringArguments(at, "split", arguments, 2, 2)
+	if err != nil {
+		return err
+	}
+
+	return stringArray(strings.Split(strs[0], strs[1]))
+}
+
+// join(array, separator) is the elements of array, as str writes them,
+// separated by separator.
+func builtinJoin(at token.Token, arguments ...object.Object) object.Object {
+	if err := checkArity(at, "join", arguments, 2, 2); err != nil {
+		return err
+	}
+
+	array, ok := arguments[0].(*object.Array)
+	if !ok {
+		return argumentTypeError(at, "join", 1, arguments[0], object.ARRAY_OBJ)
+	}
+	separator, ok := arguments[1].(*object.String)
+	if !ok {
+		return argumentTypeError(at, "join", 2, arguments[1], object.STRING_OBJ)
+	}
+
+	parts := make([]string, len(array.Elements))
+	for i, element := range array.Elements {
+		parts[i] = element.Inspect()
+	}
+
+	return &object.String{Value: strings.Join(parts, separator.Value)}
+}
+
+// trim(s, characters?) is s without the whitespace, or any of characters,
+// at its ends.
+func builtinTrim(at token.Token, arguments ...object.Object) object.Object {
+	strs, err := stringArguments(at, "trim", arguments, 1, 2)
+	if err != nil {
+		return err
+	}
+
+	if len(strs) == 2 {
+		return &object.String{Value: strings.Trim(strs[0], strs[1])}
+	}
+	return &object.String{Value: strings.TrimSpace(strs[0])}
+}
+
+// upper(s) is s in upper case, mapping each character on its own, so "ß"
+// stays as it is.
+func builtinUpper(at token.Token, arguments ...object.Object) object.Object {
+	strs, err := stringArguments(at, "upper", arguments, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	return &object.String{Value: strings.ToUpper(strs[0])}
+}
+
+// lower(s) is s in lower case.
+func builtinLower(at token.Token, arguments ...object.Object) object.Object {
+	strs, err := stringArguments(at, "lower", arguments, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	return &object.String{Value: strings.ToLower(strs[0])}
+}
+
+// replace(s, old, new, count?) is s with its first count occurrences of old,
+// or all of them, replaced with new.
+func builtinReplace(at token.Token, arguments ...object.Object) object.Object {
+	if err := checkArity(at, "replace", arguments, 3, 4); err != nil {
+		return err
+	}
+
+	strs, err := stringArguments(at, "replace", arguments[:3], 3, 3)
+	if err != nil {
+		return err
+	}
+
+	count := -1
+	if len(arguments) == 4 {
+		integer, err := integerArgument(at, "replace", 4, arguments[3])
+		if err != nil {
+			return err
+		}
+		if integer < 0 {
+			return newError(at, "negative replacement count: %d", integer)
+		}
+		count = integer
+	}
+
+	return &object.String{Value: strings.Replace(strs[0], strs[1], strs[2], count)}
+}
+
+// contains(s, substring) tells whether substring is in s.
+func builtinContains(at token.Token, arguments ...object.Object) object.Object {
+	strs, err := stringArguments(at, "contains", arguments, 2, 2)
+	if err != nil {
+		return err
+	}
+
+	return nativeBoolToBooleanObject(strings.Contains(strs[0], strs[1]))
+}
+
+// starts_with(s, prefix) tells whether s begins with prefix.
+func builtinStartsWith(at token.Token, arguments ...object.Object) object.Object {
+	strs, err := stringArguments(at, "starts_with", arguments, 2, 2)
+	if err != nil {
+		return err
+	}
+
+	return nativeBoolToBooleanObject(strings.HasPrefix(strs[0], strs[1]))
+}
+
+// ends_with(s, suffix) tells whether s ends with suffix.
+func builtinEndsWith(at token.Token, arguments ...object.Object) object.Object {
+	strs, err := stringArguments(at, "ends_with", arguments, 2, 2)
+	if err != nil {
+		return err
+	}
+
+	return nativeBoolToBooleanObject(strings.HasSuffix(strs[0], strs[1]))
+}
+
+// index_of(s, substring) is the index of the character substring first
+// starts at in s, or -1 if it is not in s.
+func builtinIndexOf(at token.Token, arguments ...object.Object) object.Object {
+	strs, err := stringArguments(at, "index_of", arguments, 2, 2)
+	if err != nil {
+		return err
+	}
+
+	index := strings.Index(strs[0], strs[1])
+	if index < 0 {
+		return &object.Integer{Value: -1}
+	}
+	return &object.Integer{Value: int64(utf8.RuneCountInString(strs[0][:index]))}
+}
+
+// repeat(s, count) is s repeated count times.
+func builtinRepeat(at token.Token, arguments ...object.Object) object.Object {
+	if err := checkArity(at, "repeat", arguments, 2, 2); err != nil {
+		return err
+	}
+
+	str, ok := arguments[0].(*object.String)
+	if !ok {
+		return argumentTypeError(at, "repeat", 1, arguments[0], object.STRING_OBJ)
+	}
+	count, err := integerArgument(at, "repeat", 2, arguments[1])
+	if err != nil {
+		return err
+	}
+
+	if count < 0 {
+		return newError(at, "negative repeat count: %d", count)
+	}
+	if count > 0 && len(str.Value) > maxStringLength/count {
+		return newError(at, "repeated string too long: %d times %d bytes", count, len(str.Value))
+	}
+
+	return &object.String{Value: strings.Repeat(str.Value, count)}
+}
+
+// pad_left(s, width, padding?) is s preceded by as much of padding, a space
+// by default, repeated as it takes for it to be width characters long.
+func builtinPadLeft(at token.Token, arguments ...object.Object) object.Object {
+	return padString(at, "pad_left", arguments, true)
+}
+
+// pad_right(s, width, padding?) is s followed by as much of padding, a space
+// by default, repeated as it takes for it to be width characters long.
+func builtinPadRight(at token.Token, arguments ...object.Object) object.Object {
+	return padString(at, "pad_right", arguments, false)
+}
+
+// chars(s) is an array of the characters of s, as strings.
+func builtinChars(at token.Token, arguments ...object.Object) object.Object {
+	strs, err := stringArguments(at, "chars", arguments, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	return stringArray(strings.Split(strs[0], ""))
+}
+
+// substring(s, start, end?) is the characters of s from index start up to,
+// but excluding, index end or the end of s.
+func builtinSubstring(at token.Token, arguments ...object.Object) object.Object {
+	if err := checkArity(at, "substring", arguments, 2, 3); err != nil {
+		return err
+	}
+
+	str, ok := arguments[0].(*object.String)
+	if !ok {
+		return argumentTypeError(at, "substring", 1, arguments[0], object.STRING_OBJ)
+	}
+
+	characters := []rune(str.Value)
+	start, err := integerArgument(at, "substring", 2, arguments[1])
+	if err != nil {
+		return err
+	}
+
+	end := len(characters)
+	if len(arguments) == 3 {
+		if end, err = integerArgument(at, "substring", 3, arguments[2]); err != nil {
+			return err
+		}
+	}
+
+	if start < 0 || end < start || end > len(characters) {
+		return newError(at, "substring bounds out of range: %d..%d (length %d)", start, end, len(characters))
+	}
+
+	return &object.String{Value: string(characters[start:end])}
+}
+
+// ============ helpers ============
+
+// stringArguments checks that between minimum and maximum strings are given
+// to the builtin name, and returns their values.
+func stringArguments(at token.Token, name string, arguments []object.Object, minimum, maximum int) ([]string, *object.Error) {
+	if err := checkArity(at, name, arguments, minimum, maximum); err != nil {
+		return nil, err
+	}
+
+	strs := make([]string, len(arguments))
+	for i, argument := range arguments {
+		str, ok := argument.(*object.String)
+		if !ok {
+			return nil, argumentTypeError(at, name, i+1, argument, object.STRING_OBJ)
+		}
+		strs[i] = str.Value
+	}
+
+	return strs, nil
+}
+
+// integerArgument returns the value of the argument at position to the
+// builtin name, which must be an integer.
+func integerArgument(at token.Token, name string, position int, argument object.Object) (int, *object.Error) {
+	integer, ok := argument.(*object.Integer)
+	if !ok {
+		return 0, argumentTypeError(at, name, position, argument, object.INTEGER_OBJ)
+	}
+	if integer.Value > math.MaxInt32 || integer.Value < math.MinInt32 {
+		return 0, newError(at, "argument %d to %s out of range: %d", position, name, integer.Value)
+	}
+
+	return int(integer.Value), nil
+}
+
+// padString pads the string argument of the builtin name on its left or its
+// right.
+func padString(at token.Token, name string, arguments []object.Object, left bool) object.Object {
+	if err := checkArity(at, name, arguments, 2, 3); err != nil {
+		return err
+	}
+
+	str, ok := arguments[0].(*object.String)
+	if !ok {
+		return argumentTypeError(at, name, 1, arguments[0], object.STRING_OBJ)
+	}
+	width, err := integerArgument(at, name, 2, arguments[1])
+	if err != nil {
+		return err
+	}
+
+	padding := []rune(" ")
+	if len(arguments) == 3 {
+		paddingString, ok := arguments[2].(*object.String)
+		if !ok {
+			return argumentTypeError(at, name, 3, arguments[2], object.STRING_OBJ)
+		}
+		if paddingString.Value == "" {
+			return newError(at, "empty padding given to %s", name)
+		}
+		padding = []rune(paddingString.Value)
+	}
+
+	missing := width - utf8.RuneCountInString(str.Value)
+	if missing <= 0 {
+		return str
+	}
+	if missing > maxStringLength/utf8.UTFMax {
+		return newError(at, "padded string too long: %d characters", width)
+	}
+
+	fill := make([]rune, missing)
+	for i := range fill {
+		fill[i] = padding[i%len(padding)]
+	}
+
+	if left {
+		return &object.String{Value: string(fill) + str.Value}
+	}
+	return &object.String{Value: str.Value + string(fill)}
+}
+
+func stringArray(strs []string) *object.Array {
+	elements := make([]object.Object, len(strs))
+	for i, str := range strs {
+		elements[i] = &object.String{Value: str}
+	}
+
+	return &object.Array{Elements: elements}
+}
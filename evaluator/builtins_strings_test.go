@@ -0,0 +1,71 @@
+package evaluator
+
+import "testing"
+
+func TestStringBuiltins(t *testing.T) {
+	tests := []resultTest{
+		{`split("a,b,,c", ",")`, source(`["a", "b", "", "c"]`)},
+		{`split("日本語", "")`, source(`["日", "本", "語"]`)},
+		{`split("a→b→c", "→")`, source(`["a", "b", "c"]`)},
+		{`split("", ",")`, source(`[""]`)},
+		{`join(["a", "b", "c"], ", ")`, "a, b, c"},
+		{`join([1, "é", true], "·")`, "1·é·true"},
+		{`join([], "-")`, ""},
+		{`trim("  \t héllo\n ")`, "héllo"},
+		{"trim(\"　全角　\")", "全角"},
+		{`trim("xxhixx", "x")`, "hi"},
+		{`trim("¡¿hola?!", "¡¿?!")`, "hola"},
+		{`upper("straße ñ")`, "STRAßE Ñ"},
+		{`lower("ÀÉÎ ΣΑΣ")`, "àéî σασ"},
+		{`replace("a-b-c", "-", "+")`, "a+b+c"},
+		{`replace("a-b-c", "-", "+", 1)`, "a+b-c"},
+		{`replace("café café", "é", "e")`, "cafe cafe"},
+		{`contains("naïve", "ïv")`, true},
+		{`contains("naïve", "iv")`, false},
+		{`starts_with("ünïcode", "ün")`, true},
+		{`starts_with("ünïcode", "un")`, false},
+		{`ends_with("日本語", "語")`, true},
+		{`ends_with("日本語", "本")`, false},
+		{`index_of("日本語", "語")`, 2},
+		{`index_of("héllo", "l")`, 2},
+		{`index_of("héllo", "z")`, -1},
+		{`index_of("abc", "")`, 0},
+		{`repeat("ab", 3)`, "ababab"},
+		{`repeat("é", 0)`, ""},
+		{`pad_left("7", 3, "0")`, "007"},
+		{`pad_left("日本", 4)`, "  日本"},
+		{`pad_right("é", 3, "·")`, "é··"},
+		{`pad_right("a", 6, "xyz")`, "axyzxy"},
+		{`pad_left("long", 2)`, "long"},
+		{`chars("añb")`, source(`["a", "ñ", "b"]`)},
+		{`chars("")`, "[]"},
+		{`len(chars("👍🏽"))`, 2},
+		{`substring("héllo wörld", 6)`, "wörld"},
+		{`substring("héllo wörld", 1, 5)`, "éllo"},
+		{`substring("日本語", 1, 2)`, "本"},
+		{`substring("abc", 3)`, ""},
+		{`"a b c" |> split(" ") |> join("-")`, "a-b-c"},
+	}
+
+	testResults(t, NewEvaluator(), tests)
+}
+
+func TestStringBuiltinErrors(t *testing.T) {
+	tests := []errorTest{
+		{`split("a")`, "wrong number of arguments to split: want=2, got=1", 6},
+		{`split("a", 1)`, "argument 2 to split must be STRING, got INTEGER", 6},
+		{`join("ab", "")`, "argument 1 to join must be ARRAY, got STRING", 5},
+		{`upper(1)`, "argument 1 to upper must be STRING, got INTEGER", 6},
+		{`replace("a", "a", "b", -1)`, "negative replacement count: -1", 8},
+		{`repeat("a", -1)`, "negative repeat count: -1", 7},
+		{`repeat("ab", 1 << 40)`, "argument 2 to repeat out of range: 1099511627776", 7},
+		{`repeat("ab", 1 << 28)`, "repeated string too long: 268435456 times 2 bytes", 7},
+		{`pad_left("a", 3, "")`, "empty padding given to pad_left", 9},
+		{`pad_right("a", "3")`, "argument 2 to pad_right must be INTEGER, got STRING", 10},
+		{`substring("日本語", 2, 4)`, "substring bounds out of range: 2..4 (length 3)", 10},
+		{`substring("abc", 2, 1)`, "substring bounds out of range: 2..1 (length 3)", 10},
+		{`substring("abc", -1)`, "substring bounds out of range: -1..3 (length 3)", 10},
+	}
+
+	testErrors(t, tests)
+}
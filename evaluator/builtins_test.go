@@ -0,0 +1,78 @@
+package evaluator
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuiltins(t *testing.T) {
+	tests := []resultTest{
+		{`len("")`, 0},
+		{`len("four")`, 4},
+		{`len("héllo, 世界")`, 9},
+		{`len([1, 2, 3])`, 3},
+		{`len({"a": 1, "b": 2})`, 2},
+		{`first([1, 2, 3])`, 1},
+		{`first([])`, nil},
+		{`last([1, 2, 3])`, 3},
+		{`last([])`, nil},
+		{`rest([1, 2, 3])`, "[2, 3]"},
+		{`rest([1])`, "[]"},
+		{`rest([])`, nil},
+		{`let xs = [1, 2, 3]; let ys = rest(xs); ys[0] = 9; xs`, "[1, 2, 3]"},
+		{`push([], 1)`, "[1]"},
+		{`let xs = [1]; push(xs, 2); xs`, "[1, 2]"},
+		{`let xs = [1]; for (x in xs) { if (x < 3) { push(xs, x + 1) } }; xs`, "[1, 2, 3]"},
+		{`type(1)`, "INTEGER"},
+		{`type("a")`, "STRING"},
+		{`type(len)`, "BUILTIN"},
+		{`type(fn() {})`, "FUNCTION"},
+		{`str(12)`, "12"},
+		{`str("a")`, "a"},
+		{`str([1, "a"])`, "[1, a]"},
+		{`str(1.50d)`, "1.50"},
+		{`[1, 2] |> len`, 2},
+		{`[1, 2, 3] |> rest() |> first()`, 2},
+		{`let len = fn(x) { 42 }; len("a")`, 42},
+		{`let f = first; f([7])`, 7},
+		{`len`, "builtin len"},
+	}
+
+	testResults(t, NewEvaluator(), tests)
+}
+
+func TestPuts(t *testing.T) {
+	assert := assert.New(t)
+
+	var output bytes.Buffer
+	e := NewEvaluator()
+	e.SetOutput(&output)
+
+	assert.Equal(NULL, testEvalWith(t, e, `puts("hello", 1, [true]); puts()`))
+	assert.Equal("hello\n1\n[true]\n", output.String())
+
+	output.Reset()
+	testEvalWith(t, e, `let a = [1]; a[0] = a; puts(a)`)
+	assert.Equal("[[...]]\n", output.String())
+}
+
+func TestBuiltinErrors(t *testing.T) {
+	tests := []errorTest{
+		{`len(1)`, "argument 1 to len must be STRING, ARRAY or HASH, got INTEGER", 4},
+		{`len("one", "two")`, "wrong number of arguments to len: want=1, got=2", 4},
+		{`len()`, "wrong number of arguments to len: want=1, got=0", 4},
+		{`first("abc")`, "argument 1 to first must be ARRAY, got STRING", 6},
+		{`last()`, "wrong number of arguments to last: want=1, got=0", 5},
+		{`rest({})`, "argument 1 to rest must be ARRAY, got HASH", 5},
+		{`push(1, 2)`, "argument 1 to push must be ARRAY, got INTEGER", 5},
+		{`push([])`, "wrong number of arguments to push: want=2, got=1", 5},
+		{`type()`, "wrong number of arguments to type: want=1, got=0", 5},
+		{`str(1, 2)`, "wrong number of arguments to str: want=1, got=2", 4},
+		{`1 |> first`, "argument 1 to first must be ARRAY, got INTEGER", 3},
+		{`lenn("a")`, "identifier not found: lenn", 1},
+	}
+
+	testErrors(t, tests)
+}
@@ -0,0 +1,82 @@
+package evaluator
+
+import (
+	"gibbon/object"
+	"gibbon/token"
+	"strings"
+	"time"
+)
+
+// Time builtins take layouts in the form of Go's time package, which writes
+// how the reference time, Mon Jan 2 15:04:05 MST 2006, would be shown, such as
+// "2006-01-02" for a date. Times are written and read in RFC 3339 unless
+// given a layout.
+
+// now() is the current time, according to the clock of the evaluator.
+func (e *Evaluator) builtinNow(at token.Token, arguments ...object.Object) object.Object {
+	if err := checkArity(at, "now", arguments, 0, 0); err != nil {
+		return err
+	}
+
+	return &object.Time{Value: e.clock()}
+}
+
+// time_parse(s, layout?) is the time s holds, written with layout. Times
+// without a time zone are in UTC.
+func builtinTimeParse(at token.Token, arguments ...object.Object) object.Object {
+	strs, err := stringArguments(at, "time_parse", arguments, 1, 2)
+	if err != nil {
+		return err
+	}
+
+	layout := time.RFC3339
+	if len(strs) == 2 {
+		layout = strs[1]
+	}
+
+	parsed, parsingErr := time.Parse(layout, strs[0])
+	if parsingErr != nil {
+		return newError(at, "invalid time: %s", parsingErr)
+	}
+
+	return &object.Time{Value: parsed}
+}
+
+// time_format(t, layout?) is t written with layout.
+func builtinTimeFormat(at token.Token, arguments ...object.Object) object.Object {
+	if err := checkArity(at, "time_format", arguments, 1, 2); err != nil {
+		return err
+	}
+
+	t, ok := arguments[0].(*object.Time)
+	if !ok {
+		return argumentTypeError(at, "time_format", 1, arguments[0], object.TIME_OBJ)
+	}
+
+	layout := time.RFC3339
+	if len(arguments) == 2 {
+		layoutString, ok := arguments[1].(*object.String)
+		if !ok {
+			return argumentTypeError(at, "time_format", 2, arguments[1], object.STRING_OBJ)
+		}
+		layout = layoutString.Value
+	}
+
+	return &object.String{Value: t.Value.Format(layout)}
+}
+
+// duration(s) is the duration s holds, a sequence of numbers each followed by
+// a unit among "ns", "us", "ms", "s", "m" and "h", such as "1h30m".
+func builtinDuration(at token.Token, arguments ...object.Object) object.Object {
+	strs, err := stringArguments(at, "duration", arguments, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	parsed, parsingErr := time.ParseDuration(strs[0])
+	if parsingErr != nil {
+		return newError(at, "%s", strings.TrimPrefix(parsingErr.Error(), "time: "))
+	}
+
+	return &object.Duration{Value: parsed}
+}
@@ -0,0 +1,91 @@
+package evaluator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeBuiltins(t *testing.T) {
+	frozen := time.Date(2024, time.May, 17, 9, 30, 0, 0, time.UTC)
+
+	tests := []resultTest{
+		{`now()`, "2024-05-17T09:30:00Z"},
+		{`now() == now()`, true},
+		{`type(now())`, "TIME"},
+		{`time_parse("2024-05-17T10:30:00+02:00")`, "2024-05-17T10:30:00+02:00"},
+		{`time_parse("2024-05-17T10:30:00.25Z")`, "2024-05-17T10:30:00.25Z"},
+		{`time_parse("17/05/2024", "02/01/2006")`, "2024-05-17T00:00:00Z"},
+		{`time_format(now(), "Jan 2, 2006 at 15:04")`, "May 17, 2024 at 09:30"},
+		{`time_format(time_parse("2024-05-17T10:30:00.25Z"))`, "2024-05-17T10:30:00Z"},
+		{`now() |> time_format("2006-01-02")`, "2024-05-17"},
+		{`duration("1h30m")`, "1h30m0s"},
+		{`duration("-1.5s")`, "-1.5s"},
+		{`type(duration("1s"))`, "DURATION"},
+		{`now() + duration("36h")`, "2024-05-18T21:30:00Z"},
+		{`duration("30m") + now()`, "2024-05-17T10:00:00Z"},
+		{`now() - duration("10m")`, "2024-05-17T09:20:00Z"},
+		{`time_parse("2024-05-18T09:30:00Z") - now()`, "24h0m0s"},
+		{`now() - time_parse("2024-05-17T11:30:00+02:00")`, "0s"},
+		{`duration("1h") + duration("15m")`, "1h15m0s"},
+		{`duration("1h") - duration("2h")`, "-1h0m0s"},
+		{`duration("90s") * 2`, "3m0s"},
+		{`3 * duration("1m")`, "3m0s"},
+		{`duration("1m") / 4`, "15s"},
+		{`let t = now(); t += duration("1s"); t`, "2024-05-17T09:30:01Z"},
+		{`now() < now() + duration("1ns")`, true},
+		{`now() > now() + duration("1ns")`, false},
+		{`now() <= now()`, true},
+		{`now() >= time_parse("2024-05-17T11:30:00+02:00")`, true},
+		{`now() == time_parse("2024-05-17T11:30:00+02:00")`, true},
+		{`now() != now() - duration("1h")`, true},
+		{`duration("1m") > duration("59s")`, true},
+		{`duration("60s") == duration("1m")`, true},
+		{`now() == null`, false},
+		{`let ts = [now() + duration("2h"), now(), now() + duration("1h")]; map(sort(ts), fn(t) { time_format(t, "15:04") })`, "[09:30, 10:30, 11:30]"},
+		{`max([duration("1s"), duration("1h"), duration("1m")])`, "1h0m0s"},
+	}
+
+	e := NewEvaluator()
+	e.SetClock(func() time.Time { return frozen })
+	testResults(t, e, tests)
+}
+
+func TestSetClock(t *testing.T) {
+	assert := assert.New(t)
+
+	current := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	e := NewEvaluator()
+	e.SetClock(func() time.Time {
+		current = current.Add(time.Second)
+		return current
+	})
+
+	assert.Equal("1s", testEvalWith(t, e, `let start = now(); now() - start`).Inspect())
+	assert.Equal("2024-01-01T00:00:03Z", testEvalWith(t, e, `now()`).Inspect())
+}
+
+func TestTimeErrors(t *testing.T) {
+	tests := []errorTest{
+		{`now(1)`, "wrong number of arguments to now: want=0, got=1", 4},
+		{`time_parse("yesterday")`, `invalid time: parsing time "yesterday" as "2006-01-02T15:04:05Z07:00": cannot parse "yesterday" as "2006"`, 11},
+		{`time_parse("2024-13-01", "2006-01-02")`, `invalid time: parsing time "2024-13-01": month out of range`, 11},
+		{`time_parse(1)`, "argument 1 to time_parse must be STRING, got INTEGER", 11},
+		{`time_format("2024-05-17")`, "argument 1 to time_format must be TIME, got STRING", 12},
+		{`time_format(now(), 1)`, "argument 2 to time_format must be STRING, got INTEGER", 12},
+		{`duration("hour")`, `invalid duration "hour"`, 9},
+		{`duration("1y")`, `unknown unit "y" in duration "1y"`, 9},
+		{`now() + 1`, "type mismatch: TIME + INTEGER", 7},
+		{`now() + now()`, "unknown operator: TIME + TIME", 7},
+		{`duration("1s") - now()`, "type mismatch: DURATION - TIME", 16},
+		{`duration("1s") * 1.5`, "type mismatch: DURATION * FLOAT", 16},
+		{`duration("1s") * duration("1s")`, "unknown operator: DURATION * DURATION", 16},
+		{`duration("1s") / 0`, "division by zero", 16},
+		{`duration("2540400h") * 2`, "duration out of range: 2540400h0m0s * 2", 22},
+		{`time_parse("2262-01-01T00:00:00Z") - time_parse("1700-01-01T00:00:00Z")`, "duration out of range: 2262-01-01T00:00:00Z - 1700-01-01T00:00:00Z", 36},
+		{`now() < duration("1s")`, "type mismatch: TIME < DURATION", 7},
+	}
+
+	testErrors(t, tests)
+}
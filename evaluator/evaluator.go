@@ -0,0 +1,1592 @@
+package evaluator
+
+import (
+	"fmt"
+	"gibbon/ast"
+	"gibbon/object"
+	"gibbon/token"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	TRUE     = &object.Boolean{Value: true}
+	FALSE    = &object.Boolean{Value: false}
+	NULL     = &object.Null{}
+	BREAK    = &object.Break{}
+	CONTINUE = &object.Continue{}
+)
+
+type Evaluator struct {
+	detectOverflow   bool                     // whether integer overflow is an error rather than a promotion
+	exactDivision    bool                     // whether dividing integers gives a rational rather than truncating
+	decimalPrecision int                      // fractional digits kept by inexact decimal results
+	roundingMode     object.RoundingMode      // how inexact decimal results are rounded
+	output           io.Writer                // where puts writes
+	builtins         map[string]object.Object // builtin functions and constants, by name
+	regexes          map[string]*object.Regex // patterns given to the regex builtins, compiled, by source
+	clock            func() time.Time         // where now reads the current time
+	callDepth        int                      // number of function calls being evaluated
+}
+
+// maxCallDepth is the number of nested function calls allowed. Go aborts the
+// whole process when its stack runs out, so runaway recursion has to be
+// stopped well before that.
+const maxCallDepth = 10000
+
+// defaultDecimalPrecision is the number of fractional digits inexact decimal
+// results are kept at, unless set otherwise.
+const defaultDecimalPrecision = 16
+
+// maxDecimalPrecision is the most fractional digits decimal results can be
+// rounded to.
+const maxDecimalPrecision = 1000
+
+func NewEvaluator() *Evaluator {
+	e := &Evaluator{
+		decimalPrecision: defaultDecimalPrecision,
+		roundingMode:     object.RoundHalfEven,
+		output:           os.Stdout,
+		regexes:          map[string]*object.Regex{},
+		clock:            time.Now,
+	}
+	e.builtins = e.newBuiltins()
+	return e
+}
+
+// DetectOverflow gives the evaluator fixed-width integers: an operation whose
+// result does not fit an int64 raises an error located at its operator,
+// instead of promoting the result to a big integer.
+func (e *Evaluator) DetectOverflow() {
+	e.detectOverflow = true
+}
+
+// SetOutput makes the puts builtin write to output rather than the standard
+// output.
+func (e *Evaluator) SetOutput(output io.Writer) {
+	e.output = output
+}
+
+// SetClock makes the now builtin read the current time from clock rather than
+// the system clock, so that tests can freeze or step time.
+func (e *Evaluator) SetClock(clock func() time.Time) {
+	e.clock = clock
+}
+
+// ExactDivision makes the division of integers with a remainder give an exact
+// rational, instead of truncating the quotient.
+func (e *Evaluator) ExactDivision() {
+	e.exactDivision = true
+}
+
+// SetDecimalPrecision sets the number of fractional digits decimal results
+// are rounded to when they cannot be exact, such as 1d / 3, and how they are
+// rounded. Exact results keep all their digits. It fails, leaving the
+// evaluator as it was, when digits is negative or above maxDecimalPrecision.
+func (e *Evaluator) SetDecimalPrecision(digits int, mode object.RoundingMode) error {
+	if digits < 0 || digits > maxDecimalPrecision {
+		return fmt.Errorf("decimal precision out of range: %d", digits)
+	}
+
+	e.decimalPrecision = digits
+	e.roundingMode = mode
+	return nil
+}
+
+// ============ evaluation ============
+
+func (e *Evaluator) Eval(node ast.Node, env *object.Environment) object.Object {
+	switch node := node.(type) {
+	case *ast.Program:
+		return e.evalProgram(node, env)
+	case *ast.ExpressionStatement:
+		return e.Eval(node.Expression, env)
+	case *ast.LetStatement:
+		return e.evalLetStatement(node, env)
+	case *ast.ReturnStatement:
+		return e.evalReturnStatement(node, env)
+	case *ast.Identifier:
+		return e.evalIdentifier(node, env)
+	case *ast.IntegerLiteral:
+		return e.evalIntegerLiteral(node, false)
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
+	case *ast.DecimalLiteral:
+		return &object.Decimal{Unscaled: node.Unscaled, Scale: node.Scale}
+	case *ast.Boolean:
+		return nativeBoolToBooleanObject(node.Value)
+	case *ast.NullLiteral:
+		return NULL
+	case *ast.PrefixExpression:
+		return e.evalPrefixExpression(node, env)
+	case *ast.InfixExpression:
+		return e.evalInfixExpression(node, env)
+	case *ast.StringLiteral:
+		return &object.String{Value: node.Value}
+	case *ast.InterpolatedString:
+		return e.evalInterpolatedString(node, env)
+	case *ast.ArrayLiteral:
+		return e.evalArrayLiteral(node, env)
+	case *ast.HashLiteral:
+		return e.evalHashLiteral(node, env)
+	case *ast.IndexExpression:
+		return e.evalIndexExpression(node, env)
+	case *ast.RangeExpression:
+		return e.evalRangeExpression(node, env)
+	case *ast.AssignExpression:
+		return e.evalAssignExpression(node, env)
+	case *ast.BlockStatement:
+		return e.evalBlockStatement(node, object.NewEnclosedEnvironment(env))
+	case *ast.IfExpression:
+		return e.evalIfExpression(node, env)
+	case *ast.WhileExpression:
+		return e.evalWhileExpression(node, env)
+	case *ast.ForInExpression:
+		return e.evalForInExpression(node, env)
+	case *ast.FunctionLiteral:
+		return &object.Function{Parameters: node.Parameters, Body: node.Body, Env: env}
+	case *ast.CallExpression:
+		return e.evalCallExpression(node, env)
+	case *ast.PipeExpression:
+		return e.evalPipeExpression(node, env)
+	case *ast.BreakStatement:
+		return BREAK
+	case *ast.ContinueStatement:
+		return CONTINUE
+	}
+
+	return nil
+}
+
+func (e *Evaluator) evalProgram(program *ast.Program, env *object.Environment) object.Object {
+	var result object.Object
+
+	for _, statement := range program.Statements {
+		result = e.Eval(statement, env)
+
+		if returnValue, ok := result.(*object.ReturnValue); ok {
+			return returnValue.Value
+		}
+
+		if interrupts(result) {
+			return result
+		}
+	}
+
+	return result
+}
+
+// evalBlockStatement evaluates to the value of the last statement of block,
+// stopping early at errors and loop control signals.
+func (e *Evaluator) evalBlockStatement(block *ast.BlockStatement, env *object.Environment) object.Object {
+	var result object.Object
+
+	for _, statement := range block.Statements {
+		result = e.Eval(statement, env)
+
+		if interrupts(result) {
+			return result
+		}
+	}
+
+	if result == nil {
+		return NULL
+	}
+
+	return result
+}
+
+func (e *Evaluator) evalIfExpression(node *ast.IfExpression, env *object.Environment) object.Object {
+	condition := e.Eval(node.Condition, env)
+	if interrupts(condition) {
+		return condition
+	}
+
+	if isTruthy(condition) {
+		return e.Eval(node.Consequence, env)
+	}
+
+	if node.Alternative != nil {
+		return e.Eval(node.Alternative, env)
+	}
+
+	return NULL
+}
+
+// evalWhileExpression runs the body of node until its condition fails or a
+// break statement is reached. Loops evaluate to null.
+func (e *Evaluator) evalWhileExpression(node *ast.WhileExpression, env *object.Environment) object.Object {
+	for {
+		condition := e.Eval(node.Condition, env)
+		if interrupts(condition) {
+			return condition
+		}
+
+		if !isTruthy(condition) {
+			return NULL
+		}
+
+		result := e.Eval(node.Body, env)
+		if result == BREAK {
+			return NULL
+		}
+
+		if result != CONTINUE && interrupts(result) {
+			return result
+		}
+	}
+}
+
+// evalForInExpression runs the body of node once for each element of its
+// iterable, binding the loop variables in a new environment every time.
+func (e *Evaluator) evalForInExpression(node *ast.ForInExpression, env *object.Environment) object.Object {
+	iterable := e.Eval(node.Iterable, env)
+	if interrupts(iterable) {
+		return iterable
+	}
+
+	collection, ok := iterable.(object.Iterable)
+	if !ok {
+		return newError(node.Token, "cannot iterate over %s", iterable.Type())
+	}
+
+	iterator := collection.Iterator()
+
+	for {
+		key, value, ok := iterator.Next()
+		if !ok {
+			return NULL
+		}
+
+		iterationEnv := object.NewEnclosedEnvironment(env)
+		if node.Key != nil {
+			iterationEnv.Set(node.Key.Value, key)
+		}
+		iterationEnv.Set(node.Value.Value, value)
+
+		result := e.evalBlockStatement(node.Body, iterationEnv)
+		if result == BREAK {
+			return NULL
+		}
+
+		if result != CONTINUE && interrupts(result) {
+			return result
+		}
+	}
+}
+
+func (e *Evaluator) evalReturnStatement(node *ast.ReturnStatement, env *object.Environment) object.Object {
+	if node.ReturnValue == nil {
+		return &object.ReturnValue{Value: NULL}
+	}
+
+	value := e.Eval(node.ReturnValue, env)
+	if interrupts(value) {
+		return value
+	}
+
+	return &object.ReturnValue{Value: value}
+}
+
+func (e *Evaluator) evalLetStatement(node *ast.LetStatement, env *object.Environment) object.Object {
+	if location, constant := env.Constant(node.Name.Value); constant && env.Declares(node.Name.Value) {
+		return newError(node.Name.Token, "cannot redeclare constant %s, declared at %d:%d",
+			node.Name.Value, location.Line, location.FirstCharIndex)
+	}
+
+	value := e.Eval(node.Value, env)
+	if interrupts(value) {
+		return value
+	}
+
+	if node.Constant {
+		env.SetConstant(node.Name.Value, value, node.Name.Token.Location)
+	} else {
+		env.Set(node.Name.Value, value)
+	}
+
+	return nil
+}
+
+func (e *Evaluator) evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
+	if value, ok := env.Get(node.Value); ok {
+		return value
+	}
+
+	if builtin, ok := e.builtins[node.Value]; ok {
+		return builtin
+	}
+
+	return newError(node.Token, "identifier not found: %s", node.Value)
+}
+
+func (e *Evaluator) evalIntegerLiteral(node *ast.IntegerLiteral, negated bool) object.Object {
+	if node.Big == nil {
+		if negated {
+			return &object.Integer{Value: -node.Value}
+		}
+		return &object.Integer{Value: node.Value}
+	}
+
+	value := node.Big
+	if negated {
+		value = new(big.Int).Neg(value)
+	}
+	if e.detectOverflow && !value.IsInt64() {
+		return newError(node.Token, "integer overflow: %s", value)
+	}
+
+	return bigIntegerObject(value)
+}
+
+func (e *Evaluator) evalPrefixExpression(node *ast.PrefixExpression, env *object.Environment) object.Object {
+	// the literal is negated before its range is checked, so that the lowest
+	// int64 can be written
+	if literal, ok := node.Right.(*ast.IntegerLiteral); ok && node.Token.Type == token.MINUS {
+		return e.evalIntegerLiteral(literal, true)
+	}
+
+	right := e.Eval(node.Right, env)
+	if interrupts(right) {
+		return right
+	}
+
+	switch node.Token.Type {
+	case token.BANG:
+		return nativeBoolToBooleanObject(!isTruthy(right))
+	case token.MINUS:
+		switch integer := right.(type) {
+		case *object.Integer:
+			if integer.Value == math.MinInt64 {
+				if e.detectOverflow {
+					return newError(node.Token, "integer overflow: -(%d)", integer.Value)
+				}
+				return bigIntegerObject(new(big.Int).Neg(big.NewInt(integer.Value)))
+			}
+			return &object.Integer{Value: -integer.Value}
+		case *object.BigInteger:
+			return bigIntegerObject(new(big.Int).Neg(integer.Value))
+		case *object.Rational:
+			return &object.Rational{Value: new(big.Rat).Neg(integer.Value)}
+		case *object.Decimal:
+			return &object.Decimal{Unscaled: new(big.Int).Neg(integer.Unscaled), Scale: integer.Scale}
+		case *object.Float:
+			return &object.Float{Value: -integer.Value}
+		}
+	case token.PLUS:
+		if isNumeric(right) {
+			return right
+		}
+	case token.TILDE:
+		switch integer := right.(type) {
+		case *object.Integer:
+			return &object.Integer{Value: ^integer.Value}
+		case *object.BigInteger:
+			return bigIntegerObject(new(big.Int).Not(integer.Value))
+		}
+	}
+
+	return newError(node.Token, "unknown operator: %s%s", node.Operator, right.Type())
+}
+
+func (e *Evaluator) evalInfixExpression(node *ast.InfixExpression, env *object.Environment) object.Object {
+	if node.Token.Type == token.AND || node.Token.Type == token.OR {
+		return e.evalLogicalExpression(node, env)
+	}
+
+	if node.Token.Type == token.NULL_COALESCE {
+		return e.evalNullCoalescingExpression(node, env)
+	}
+
+	left := e.Eval(node.Left, env)
+	if interrupts(left) {
+		return left
+	}
+
+	right := e.Eval(node.Right, env)
+	if interrupts(right) {
+		return right
+	}
+
+	return e.evalInfixOperation(node.Token, left, right)
+}
+
+// evalLogicalExpression only evaluates the right operand when the left one
+// does not already decide the result.
+func (e *Evaluator) evalLogicalExpression(node *ast.InfixExpression, env *object.Environment) object.Object {
+	left := e.Eval(node.Left, env)
+	if interrupts(left) {
+		return left
+	}
+
+	if node.Token.Type == token.AND && !isTruthy(left) {
+		return FALSE
+	}
+
+	if node.Token.Type == token.OR && isTruthy(left) {
+		return TRUE
+	}
+
+	right := e.Eval(node.Right, env)
+	if interrupts(right) {
+		return right
+	}
+
+	return nativeBoolToBooleanObject(isTruthy(right))
+}
+
+// evalNullCoalescingExpression only evaluates the right operand when the left
+// one is null.
+func (e *Evaluator) evalNullCoalescingExpression(node *ast.InfixExpression, env *object.Environment) object.Object {
+	left := e.Eval(node.Left, env)
+	if left != NULL {
+		return left
+	}
+
+	return e.Eval(node.Right, env)
+}
+
+// evalInterpolatedString joins the Inspect() representations of the parts of
+// node, so strings are inserted as they are and other values as printed.
+func (e *Evaluator) evalInterpolatedString(node *ast.InterpolatedString, env *object.Environment) object.Object {
+	var out strings.Builder
+
+	for _, part := range node.Parts {
+		evaluated := e.Eval(part, env)
+		if interrupts(evaluated) {
+			return evaluated
+		}
+
+		out.WriteString(evaluated.Inspect())
+	}
+
+	return &object.String{Value: out.String()}
+}
+
+func (e *Evaluator) evalArrayLiteral(node *ast.ArrayLiteral, env *object.Environment) object.Object {
+	elements := make([]object.Object, 0, len(node.Elements))
+
+	for _, element := range node.Elements {
+		evaluated := e.Eval(element, env)
+		if interrupts(evaluated) {
+			return evaluated
+		}
+
+		elements = append(elements, evaluated)
+	}
+
+	return &object.Array{Elements: elements}
+}
+
+func (e *Evaluator) evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
+	hash := object.NewHash()
+
+	for _, pair := range node.Pairs {
+		key := e.Eval(pair.Key, env)
+		if interrupts(key) {
+			return key
+		}
+
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return newError(node.Token, "unusable as hash key: %s", key.Type())
+		}
+
+		value := e.Eval(pair.Value, env)
+		if interrupts(value) {
+			return value
+		}
+
+		hash.Set(hashKey, value)
+	}
+
+	return hash
+}
+
+func (e *Evaluator) evalIndexExpression(node *ast.IndexExpression, env *object.Environment) object.Object {
+	result, _ := e.evalIndexChain(node, env)
+	return result
+}
+
+// evalIndexChain evaluates node, also telling whether an optional access in
+// the chain of index and call expressions ending at node found null. The rest
+// of the chain is then skipped, so "a?.b[0]" and "a?.f(1)" are null rather
+// than errors when a is.
+func (e *Evaluator) evalIndexChain(node *ast.IndexExpression, env *object.Environment) (object.Object, bool) {
+	left, skipped := e.evalChainLink(node.Left, env)
+	if skipped {
+		return NULL, true
+	}
+
+	if interrupts(left) {
+		return left, false
+	}
+
+	if node.Optional && left == NULL {
+		return NULL, true
+	}
+
+	var index object.Object
+	if field, ok := node.Index.(*ast.Identifier); ok && node.Token.Type == token.OPTIONAL_DOT {
+		index = &object.String{Value: field.Value}
+	} else {
+		index = e.Eval(node.Index, env)
+	}
+
+	if interrupts(index) {
+		return index, false
+	}
+
+	return evalIndex(node.Token, left, index), false
+}
+
+// evalChainLink evaluates node, the left part of an index or call expression,
+// telling whether an optional access in it found null when it is itself an
+// index or call expression.
+func (e *Evaluator) evalChainLink(node ast.Expression, env *object.Environment) (object.Object, bool) {
+	switch node := node.(type) {
+	case *ast.IndexExpression:
+		return e.evalIndexChain(node, env)
+	case *ast.CallExpression:
+		return e.evalCall(node, nil, env)
+	}
+
+	return e.Eval(node, env), false
+}
+
+func (e *Evaluator) evalCallExpression(node *ast.CallExpression, env *object.Environment) object.Object {
+	result, _ := e.evalCall(node, nil, env)
+	return result
+}
+
+// evalPipeExpression calls the right operand of node with the left one as its
+// first argument, before the arguments the right operand is called with if it
+// is a call written without parentheses around it.
+func (e *Evaluator) evalPipeExpression(node *ast.PipeExpression, env *object.Environment) object.Object {
+	left := e.Eval(node.Left, env)
+	if interrupts(left) {
+		return left
+	}
+
+	if call, ok := node.Right.(*ast.CallExpression); ok && !call.Grouped {
+		result, _ := e.evalCall(call, left, env)
+		return result
+	}
+
+	function := e.Eval(node.Right, env)
+	if interrupts(function) {
+		return function
+	}
+
+	return e.applyFunction(node.Token, function, []object.Object{left})
+}
+
+// evalCall calls the function of call with its arguments, preceded by first
+// unless it is nil, also telling whether an optional access in the chain
+// giving the function found null, in which case nothing is called.
+func (e *Evaluator) evalCall(
+	call *ast.CallExpression,
+	first object.Object,
+	env *object.Environment,
+) (object.Object, bool) {
+	function, skipped := e.evalChainLink(call.Function, env)
+	if skipped {
+		return NULL, true
+	}
+
+	if interrupts(function) {
+		return function, false
+	}
+
+	arguments := e.evalExpressions(call.Arguments, env)
+	if len(arguments) == 1 && interrupts(arguments[0]) {
+		return arguments[0], false
+	}
+
+	if first != nil {
+		arguments = append([]object.Object{first}, arguments...)
+	}
+
+	return e.applyFunction(call.Token, function, arguments), false
+}
+
+// evalExpressions evaluates expressions in order, returning only the first
+// interruption met, if any.
+func (e *Evaluator) evalExpressions(expressions []ast.Expression, env *object.Environment) []object.Object {
+	results := make([]object.Object, 0, len(expressions))
+
+	for _, expression := range expressions {
+		evaluated := e.Eval(expression, env)
+		if interrupts(evaluated) {
+			return []object.Object{evaluated}
+		}
+
+		results = append(results, evaluated)
+	}
+
+	return results
+}
+
+// applyFunction calls function with arguments, errors being located at the
+// call site at.
+func (e *Evaluator) applyFunction(at token.Token, function object.Object, arguments []object.Object) object.Object {
+	if builtin, ok := function.(*object.Builtin); ok {
+		return builtin.Fn(at, arguments...)
+	}
+
+	fn, ok := function.(*object.Function)
+	if !ok {
+		return newError(at, "not a function: %s", function.Type())
+	}
+
+	if len(arguments) != len(fn.Parameters) {
+		return newError(at, "wrong number of arguments: want=%d, got=%d", len(fn.Parameters), len(arguments))
+	}
+
+	if e.callDepth >= maxCallDepth {
+		return newError(at, "maximum call depth exceeded: %d", maxCallDepth)
+	}
+	e.callDepth++
+	defer func() { e.callDepth-- }()
+
+	env := object.NewEnclosedEnvironment(fn.Env)
+	for i, parameter := range fn.Parameters {
+		env.Set(parameter.Value, arguments[i])
+	}
+
+	result := e.evalBlockStatement(fn.Body, env)
+	if returnValue, ok := result.(*object.ReturnValue); ok {
+		return returnValue.Value
+	}
+
+	return result
+}
+
+func (e *Evaluator) evalRangeExpression(node *ast.RangeExpression, env *object.Environment) object.Object {
+	start := e.Eval(node.Start, env)
+	if interrupts(start) {
+		return start
+	}
+
+	end := e.Eval(node.End, env)
+	if interrupts(end) {
+		return end
+	}
+
+	startInteger, startOk := start.(*object.Integer)
+	endInteger, endOk := end.(*object.Integer)
+	if !startOk || !endOk {
+		return newError(node.Token, "range bounds must be %s, got %s%s%s",
+			object.INTEGER_OBJ, start.Type(), node.Token.Literal, end.Type())
+	}
+
+	return &object.Range{Start: startInteger.Value, End: endInteger.Value, Inclusive: node.Inclusive}
+}
+
+func (e *Evaluator) evalAssignExpression(node *ast.AssignExpression, env *object.Environment) object.Object {
+	switch target := node.Target.(type) {
+	case *ast.Identifier:
+		return e.evalIdentifierAssignment(node, target, env)
+	case *ast.IndexExpression:
+		return e.evalIndexAssignment(node, target, env)
+	}
+
+	return newError(node.Token, "cannot assign to %s", node.Target)
+}
+
+func (e *Evaluator) evalIdentifierAssignment(
+	node *ast.AssignExpression,
+	target *ast.Identifier,
+	env *object.Environment,
+) object.Object {
+	current, declared := env.Get(target.Value)
+	if !declared {
+		return newError(target.Token, "assignment to undeclared identifier: %s", target.Value)
+	}
+
+	if location, constant := env.Constant(target.Value); constant {
+		return newError(target.Token, "cannot assign to constant %s, declared at %d:%d",
+			target.Value, location.Line, location.FirstCharIndex)
+	}
+
+	value := e.evalAssignedValue(node, current, env)
+	if interrupts(value) {
+		return value
+	}
+
+	env.Assign(target.Value, value)
+	return value
+}
+
+func (e *Evaluator) evalIndexAssignment(
+	node *ast.AssignExpression,
+	target *ast.IndexExpression,
+	env *object.Environment,
+) object.Object {
+	container := e.Eval(target.Left, env)
+	if interrupts(container) {
+		return container
+	}
+
+	index := e.Eval(target.Index, env)
+	if interrupts(index) {
+		return index
+	}
+
+	// unlike reading past the end of an array, writing there is an error
+	if array, ok := container.(*object.Array); ok {
+		if _, err := arrayIndex(target.Token, array, index); err != nil {
+			return err
+		}
+	}
+
+	var current object.Object
+	if node.Token.Type != token.ASSIGN {
+		current = evalIndex(target.Token, container, index)
+		if interrupts(current) {
+			return current
+		}
+	}
+
+	value := e.evalAssignedValue(node, current, env)
+	if interrupts(value) {
+		return value
+	}
+
+	switch container := container.(type) {
+	case *object.Array:
+		i, err := arrayIndex(target.Token, container, index)
+		if err != nil {
+			return err
+		}
+		container.Elements[i] = value
+	case *object.Hash:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError(target.Token, "unusable as hash key: %s", index.Type())
+		}
+		container.Set(key, value)
+	default:
+		return newError(target.Token, "index assignment not supported: %s", container.Type())
+	}
+
+	return value
+}
+
+// evalAssignedValue evaluates the value assigned by node, combining it with
+// the current value of the target for compound assignments such as "+=".
+func (e *Evaluator) evalAssignedValue(
+	node *ast.AssignExpression,
+	current object.Object,
+	env *object.Environment,
+) object.Object {
+	value := e.Eval(node.Value, env)
+	if interrupts(value) || node.Token.Type == token.ASSIGN {
+		return value
+	}
+
+	operatorType := compoundAssignmentOperators[node.Token.Type]
+	operator := token.Token{Type: operatorType, Literal: operatorType.Lexeme(), Location: node.Token.Location}
+
+	return e.evalInfixOperation(operator, current, value)
+}
+
+// compoundAssignmentOperators maps each compound assignment operator to the
+// operator it applies
+var compoundAssignmentOperators = map[token.TokenType]token.TokenType{
+	token.PLUS_ASSIGN:     token.PLUS,
+	token.MINUS_ASSIGN:    token.MINUS,
+	token.ASTERISK_ASSIGN: token.ASTERISK,
+	token.SLASH_ASSIGN:    token.SLASH,
+	token.PERCENT_ASSIGN:  token.PERCENT,
+}
+
+func (e *Evaluator) evalInfixOperation(operator token.Token, left, right object.Object) object.Object {
+	switch {
+	case operator.Type == token.IN:
+		return e.evalMembership(operator, left, right)
+	case (left == NULL || right == NULL) && operator.Type == token.EQUAL:
+		return nativeBoolToBooleanObject(left == right)
+	case (left == NULL || right == NULL) && operator.Type == token.DIFFERENT:
+		return nativeBoolToBooleanObject(left != right)
+	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
+		return e.evalIntegerInfixExpression(operator, left.(*object.Integer), right.(*object.Integer))
+	case isInteger(left) && isInteger(right):
+		return e.evalBigIntegerInfixExpression(operator, toBigInt(left), toBigInt(right))
+	case left.Type() == object.FLOAT_OBJ && (right.Type() == object.FLOAT_OBJ || isInteger(right)),
+		right.Type() == object.FLOAT_OBJ && isInteger(left):
+		return evalFloatInfixExpression(operator, left, right)
+	case isNumber(left) && isNumber(right):
+		return e.evalNumberInfixExpression(operator, left, right)
+	case left.Type() == object.BOOLEAN_OBJ && right.Type() == object.BOOLEAN_OBJ:
+		return evalBooleanInfixExpression(operator, left.(*object.Boolean), right.(*object.Boolean))
+	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
+		return evalStringInfixExpression(operator, left.(*object.String), right.(*object.String))
+	case isTemporal(left) || isTemporal(right):
+		return evalTimeInfixExpression(operator, left, right)
+	case left.Type() != right.Type():
+		return newError(operator, "type mismatch: %s %s %s", left.Type(), operator.Literal, right.Type())
+	}
+
+	return newError(operator, "unknown operator: %s %s %s", left.Type(), operator.Literal, right.Type())
+}
+
+// evalIntegerInfixExpression operates on int64 values, handing the operation
+// over to math/big whenever its result would not fit one, unless overflow is
+// detected.
+func (e *Evaluator) evalIntegerInfixExpression(operator token.Token, left, right *object.Integer) object.Object {
+	overflows := func() object.Object {
+		if e.detectOverflow {
+			return newError(operator, "integer overflow: %d %s %d", left.Value, operator.Literal, right.Value)
+		}
+		return e.evalBigIntegerInfixExpression(operator, big.NewInt(left.Value), big.NewInt(right.Value))
+	}
+
+	switch operator.Type {
+	case token.PLUS:
+		sum := left.Value + right.Value
+		if (sum > left.Value) != (right.Value > 0) {
+			return overflows()
+		}
+		return &object.Integer{Value: sum}
+	case token.MINUS:
+		difference := left.Value - right.Value
+		if (difference < left.Value) != (right.Value > 0) {
+			return overflows()
+		}
+		return &object.Integer{Value: difference}
+	case token.ASTERISK:
+		product, ok := multiplyIntegers(left.Value, right.Value)
+		if !ok {
+			return overflows()
+		}
+		return &object.Integer{Value: product}
+	case token.SLASH:
+		if right.Value == 0 {
+			return newError(operator, "division by zero")
+		}
+		if e.exactDivision && left.Value%right.Value != 0 {
+			return &object.Rational{Value: big.NewRat(left.Value, right.Value)}
+		}
+		if left.Value == math.MinInt64 && right.Value == -1 {
+			return overflows()
+		}
+		return &object.Integer{Value: left.Value / right.Value}
+	case token.PERCENT:
+		if right.Value == 0 {
+			return newError(operator, "modulo by zero")
+		}
+		return &object.Integer{Value: left.Value % right.Value}
+	case token.POWER:
+		if right.Value < 0 {
+			return newError(operator, "negative exponent: %d", right.Value)
+		}
+		power, ok := integerPower(left.Value, right.Value)
+		if !ok {
+			return overflows()
+		}
+		return &object.Integer{Value: power}
+	case token.BIT_AND:
+		return &object.Integer{Value: left.Value & right.Value}
+	case token.BIT_OR:
+		return &object.Integer{Value: left.Value | right.Value}
+	case token.BIT_XOR:
+		return &object.Integer{Value: left.Value ^ right.Value}
+	case token.SHIFT_LEFT, token.SHIFT_RIGHT:
+		if right.Value < 0 {
+			return newError(operator, "negative shift count: %d", right.Value)
+		}
+		if operator.Type == token.SHIFT_LEFT {
+			shifted := left.Value << right.Value
+			if shifted>>right.Value != left.Value {
+				return overflows()
+			}
+			return &object.Integer{Value: shifted}
+		}
+		return &object.Integer{Value: left.Value >> right.Value}
+	case token.LT:
+		return nativeBoolToBooleanObject(left.Value < right.Value)
+	case token.GT:
+		return nativeBoolToBooleanObject(left.Value > right.Value)
+	case token.LTE:
+		return nativeBoolToBooleanObject(left.Value <= right.Value)
+	case token.GTE:
+		return nativeBoolToBooleanObject(left.Value >= right.Value)
+	case token.EQUAL:
+		return nativeBoolToBooleanObject(left.Value == right.Value)
+	case token.DIFFERENT:
+		return nativeBoolToBooleanObject(left.Value != right.Value)
+	}
+
+	return newError(operator, "unknown operator: %s %s %s", left.Type(), operator.Literal, right.Type())
+}
+
+// evalBigIntegerInfixExpression operates on arbitrary-precision values, either
+// operand being an Integer or a BigInteger.
+func (e *Evaluator) evalBigIntegerInfixExpression(operator token.Token, left, right *big.Int) object.Object {
+	switch operator.Type {
+	case token.PLUS:
+		return bigIntegerObject(new(big.Int).Add(left, right))
+	case token.MINUS:
+		return bigIntegerObject(new(big.Int).Sub(left, right))
+	case token.ASTERISK:
+		return bigIntegerObject(new(big.Int).Mul(left, right))
+	case token.SLASH:
+		if right.Sign() == 0 {
+			return newError(operator, "division by zero")
+		}
+		if e.exactDivision {
+			return rationalObject(new(big.Rat).SetFrac(left, right))
+		}
+		return bigIntegerObject(new(big.Int).Quo(left, right))
+	case token.PERCENT:
+		if right.Sign() == 0 {
+			return newError(operator, "modulo by zero")
+		}
+		return bigIntegerObject(new(big.Int).Rem(left, right))
+	case token.POWER:
+		if right.Sign() < 0 {
+			return newError(operator, "negative exponent: %s", right)
+		}
+		if !right.IsInt64() || exceedsBigIntegerBits(int64(left.BitLen()-1), right.Int64()) {
+			return newError(operator, "integer too large: %s ** %s", left, right)
+		}
+		return bigIntegerObject(new(big.Int).Exp(left, right, nil))
+	case token.BIT_AND:
+		return bigIntegerObject(new(big.Int).And(left, right))
+	case token.BIT_OR:
+		return bigIntegerObject(new(big.Int).Or(left, right))
+	case token.BIT_XOR:
+		return bigIntegerObject(new(big.Int).Xor(left, right))
+	case token.SHIFT_LEFT, token.SHIFT_RIGHT:
+		if right.Sign() < 0 {
+			return newError(operator, "negative shift count: %s", right)
+		}
+		if operator.Type == token.SHIFT_RIGHT {
+			if !right.IsUint64() || right.Uint64() > uint64(left.BitLen()) {
+				// every significant bit is shifted out, leaving the sign
+				if left.Sign() < 0 {
+					return &object.Integer{Value: -1}
+				}
+				return &object.Integer{Value: 0}
+			}
+			return bigIntegerObject(new(big.Int).Rsh(left, uint(right.Uint64())))
+		}
+		if left.Sign() == 0 {
+			return &object.Integer{Value: 0}
+		}
+		if !right.IsInt64() || exceedsBigIntegerBits(1, int64(left.BitLen())+right.Int64()) {
+			return newError(operator, "integer too large: %s << %s", left, right)
+		}
+		return bigIntegerObject(new(big.Int).Lsh(left, uint(right.Uint64())))
+	case token.LT:
+		return nativeBoolToBooleanObject(left.Cmp(right) < 0)
+	case token.GT:
+		return nativeBoolToBooleanObject(left.Cmp(right) > 0)
+	case token.LTE:
+		return nativeBoolToBooleanObject(left.Cmp(right) <= 0)
+	case token.GTE:
+		return nativeBoolToBooleanObject(left.Cmp(right) >= 0)
+	case token.EQUAL:
+		return nativeBoolToBooleanObject(left.Cmp(right) == 0)
+	case token.DIFFERENT:
+		return nativeBoolToBooleanObject(left.Cmp(right) != 0)
+	}
+
+	return newError(operator, "unknown operator: %s %s %s",
+		bigIntegerObject(left).Type(), operator.Literal, bigIntegerObject(right).Type())
+}
+
+// evalNumberInfixExpression operates on numbers of which at least one is a
+// rational or a decimal. The operation is done on exact fractions, then gives
+// a decimal if either operand is one, or a rational otherwise.
+func (e *Evaluator) evalNumberInfixExpression(operator token.Token, left, right object.Object) object.Object {
+	leftValue, rightValue := toRat(left), toRat(right)
+	leftScale, rightScale := decimalScale(left), decimalScale(right)
+	scale := maxInt(leftScale, rightScale)
+
+	var result *big.Rat
+	switch operator.Type {
+	case token.PLUS:
+		result = new(big.Rat).Add(leftValue, rightValue)
+	case token.MINUS:
+		result = new(big.Rat).Sub(leftValue, rightValue)
+	case token.ASTERISK:
+		result = new(big.Rat).Mul(leftValue, rightValue)
+		scale = leftScale + rightScale
+	case token.SLASH:
+		if rightValue.Sign() == 0 {
+			return newError(operator, "division by zero")
+		}
+		result = new(big.Rat).Quo(leftValue, rightValue)
+	case token.PERCENT:
+		if rightValue.Sign() == 0 {
+			return newError(operator, "modulo by zero")
+		}
+		// the remainder takes the sign of the dividend, as with integers
+		quotient := new(big.Rat).Quo(leftValue, rightValue)
+		truncated := new(big.Rat).SetInt(new(big.Int).Quo(quotient.Num(), quotient.Denom()))
+		result = new(big.Rat).Sub(leftValue, truncated.Mul(truncated, rightValue))
+	case token.POWER:
+		exponent, ok := right.(*object.Integer)
+		if !ok {
+			return newError(operator, "exponent must be %s, got %s", object.INTEGER_OBJ, right.Type())
+		}
+		if leftValue.Sign() == 0 && exponent.Value < 0 {
+			return newError(operator, "division by zero")
+		}
+		magnitude := exponent.Value
+		if magnitude < 0 {
+			magnitude = -magnitude
+		}
+		bits := maxInt(maxInt(leftValue.Num().BitLen(), leftValue.Denom().BitLen()), leftScale)
+		if magnitude < 0 || exceedsBigIntegerBits(int64(bits), magnitude) {
+			return newError(operator, "number too large: %s ** %d", left.Inspect(), exponent.Value)
+		}
+		result = ratPower(leftValue, exponent.Value)
+		if exponent.Value > 0 {
+			scale = leftScale * int(exponent.Value)
+		}
+	case token.LT:
+		return nativeBoolToBooleanObject(leftValue.Cmp(rightValue) < 0)
+	case token.GT:
+		return nativeBoolToBooleanObject(leftValue.Cmp(rightValue) > 0)
+	case token.LTE:
+		return nativeBoolToBooleanObject(leftValue.Cmp(rightValue) <= 0)
+	case token.GTE:
+		return nativeBoolToBooleanObject(leftValue.Cmp(rightValue) >= 0)
+	case token.EQUAL:
+		return nativeBoolToBooleanObject(leftValue.Cmp(rightValue) == 0)
+	case token.DIFFERENT:
+		return nativeBoolToBooleanObject(leftValue.Cmp(rightValue) != 0)
+	default:
+		return newError(operator, "unknown operator: %s %s %s", left.Type(), operator.Literal, right.Type())
+	}
+
+	if left.Type() == object.DECIMAL_OBJ || right.Type() == object.DECIMAL_OBJ {
+		return e.decimalObject(result, scale)
+	}
+	return rationalObject(result)
+}
+
+// decimalObject gives value as a decimal of scale fractional digits when it
+// has no more than that. Otherwise, it is rounded to the decimal precision,
+// and only keeps the trailing zeros within scale.
+func (e *Evaluator) decimalObject(value *big.Rat, scale int) *object.Decimal {
+	exact := object.NewDecimal(value, scale, object.RoundDown)
+	if exact.Rat().Cmp(value) == 0 {
+		return exact
+	}
+
+	rounded := object.NewDecimal(value, maxInt(scale, e.decimalPrecision), e.roundingMode)
+	ten := big.NewInt(10)
+	for rounded.Scale > scale {
+		quotient, remainder := new(big.Int).QuoRem(rounded.Unscaled, ten, new(big.Int))
+		if remainder.Sign() != 0 {
+			break
+		}
+		rounded.Unscaled, rounded.Scale = quotient, rounded.Scale-1
+	}
+
+	return rounded
+}
+
+// evalFloatInfixExpression operates on floats, either operand possibly being
+// an integer instead. Floats do not mix with the exact rationals and decimals.
+func evalFloatInfixExpression(operator token.Token, leftObject, rightObject object.Object) object.Object {
+	left, right := toFloat(leftObject), toFloat(rightObject)
+
+	switch operator.Type {
+	case token.PLUS:
+		return &object.Float{Value: left + right}
+	case token.MINUS:
+		return &object.Float{Value: left - right}
+	case token.ASTERISK:
+		return &object.Float{Value: left * right}
+	case token.SLASH:
+		if right == 0 {
+			return newError(operator, "division by zero")
+		}
+		return &object.Float{Value: left / right}
+	case token.PERCENT:
+		if right == 0 {
+			return newError(operator, "modulo by zero")
+		}
+		return &object.Float{Value: math.Mod(left, right)}
+	case token.POWER:
+		return floatPower(operator, leftObject, rightObject)
+	case token.LT:
+		return nativeBoolToBooleanObject(left < right)
+	case token.GT:
+		return nativeBoolToBooleanObject(left > right)
+	case token.LTE:
+		return nativeBoolToBooleanObject(left <= right)
+	case token.GTE:
+		return nativeBoolToBooleanObject(left >= right)
+	case token.EQUAL:
+		return nativeBoolToBooleanObject(left == right)
+	case token.DIFFERENT:
+		return nativeBoolToBooleanObject(left != right)
+	}
+
+	return newError(operator, "unknown operator: %s %s %s", leftObject.Type(), operator.Literal, rightObject.Type())
+}
+
+func evalBooleanInfixExpression(operator token.Token, left, right *object.Boolean) object.Object {
+	switch operator.Type {
+	case token.EQUAL:
+		return nativeBoolToBooleanObject(left == right)
+	case token.DIFFERENT:
+		return nativeBoolToBooleanObject(left != right)
+	}
+
+	return newError(operator, "unknown operator: %s %s %s", left.Type(), operator.Literal, right.Type())
+}
+
+func evalStringInfixExpression(operator token.Token, left, right *object.String) object.Object {
+	switch operator.Type {
+	case token.PLUS:
+		return &object.String{Value: left.Value + right.Value}
+	case token.EQUAL:
+		return nativeBoolToBooleanObject(left.Value == right.Value)
+	case token.DIFFERENT:
+		return nativeBoolToBooleanObject(left.Value != right.Value)
+	}
+
+	return newError(operator, "unknown operator: %s %s %s", left.Type(), operator.Literal, right.Type())
+}
+
+// evalTimeInfixExpression operates on times and durations. Durations are
+// added to times and to each other, and scaled by integers, while subtracting
+// a time from another gives the duration between them. Times, like durations,
+// compare with one another.
+func evalTimeInfixExpression(operator token.Token, leftObject, rightObject object.Object) object.Object {
+	outOfRange := func() object.Object {
+		return newError(operator, "duration out of range: %s %s %s",
+			leftObject.Inspect(), operator.Literal, rightObject.Inspect())
+	}
+
+	switch left := leftObject.(type) {
+	case *object.Time:
+		switch right := rightObject.(type) {
+		case *object.Time:
+			if operator.Type == token.MINUS {
+				difference := left.Value.Sub(right.Value)
+				if difference == math.MaxInt64 || difference == math.MinInt64 {
+					return outOfRange()
+				}
+				return &object.Duration{Value: difference}
+			}
+			if result, ok := evalComparison(operator, left.Value.Compare(right.Value)); ok {
+				return result
+			}
+		case *object.Duration:
+			switch operator.Type {
+			case token.PLUS:
+				return &object.Time{Value: left.Value.Add(right.Value)}
+			case token.MINUS:
+				if right.Value == math.MinInt64 {
+					return outOfRange()
+				}
+				return &object.Time{Value: left.Value.Add(-right.Value)}
+			}
+		}
+	case *object.Duration:
+		switch right := rightObject.(type) {
+		case *object.Duration:
+			switch operator.Type {
+			case token.PLUS:
+				sum := left.Value + right.Value
+				if (sum > left.Value) != (right.Value > 0) {
+					return outOfRange()
+				}
+				return &object.Duration{Value: sum}
+			case token.MINUS:
+				difference := left.Value - right.Value
+				if (difference < left.Value) != (right.Value > 0) {
+					return outOfRange()
+				}
+				return &object.Duration{Value: difference}
+			}
+			if result, ok := evalComparison(operator, compareDurations(left.Value, right.Value)); ok {
+				return result
+			}
+		case *object.Time:
+			if operator.Type == token.PLUS {
+				return &object.Time{Value: right.Value.Add(left.Value)}
+			}
+		case *object.Integer:
+			switch operator.Type {
+			case token.ASTERISK:
+				product, ok := multiplyIntegers(int64(left.Value), right.Value)
+				if !ok {
+					return outOfRange()
+				}
+				return &object.Duration{Value: time.Duration(product)}
+			case token.SLASH:
+				if right.Value == 0 {
+					return newError(operator, "division by zero")
+				}
+				if right.Value == -1 && left.Value == math.MinInt64 {
+					return outOfRange()
+				}
+				return &object.Duration{Value: left.Value / time.Duration(right.Value)}
+			}
+		}
+	case *object.Integer:
+		if right, ok := rightObject.(*object.Duration); ok && operator.Type == token.ASTERISK {
+			return evalTimeInfixExpression(operator, right, left)
+		}
+	}
+
+	if leftObject.Type() != rightObject.Type() {
+		return newError(operator, "type mismatch: %s %s %s", leftObject.Type(), operator.Literal, rightObject.Type())
+	}
+	return newError(operator, "unknown operator: %s %s %s", leftObject.Type(), operator.Literal, rightObject.Type())
+}
+
+// evalComparison applies the comparison operator to operands that compare as
+// cmp, negative when the left one is less, ok being false when operator does
+// not compare.
+func evalComparison(operator token.Token, cmp int) (result object.Object, ok bool) {
+	switch operator.Type {
+	case token.LT:
+		return nativeBoolToBooleanObject(cmp < 0), true
+	case token.GT:
+		return nativeBoolToBooleanObject(cmp > 0), true
+	case token.LTE:
+		return nativeBoolToBooleanObject(cmp <= 0), true
+	case token.GTE:
+		return nativeBoolToBooleanObject(cmp >= 0), true
+	case token.EQUAL:
+		return nativeBoolToBooleanObject(cmp == 0), true
+	case token.DIFFERENT:
+		return nativeBoolToBooleanObject(cmp != 0), true
+	}
+
+	return nil, false
+}
+
+func compareDurations(left, right time.Duration) int {
+	switch {
+	case left < right:
+		return -1
+	case left > right:
+		return 1
+	}
+	return 0
+}
+
+// isTemporal tells whether obj is a time or a duration.
+func isTemporal(obj object.Object) bool {
+	return obj.Type() == object.TIME_OBJ || obj.Type() == object.DURATION_OBJ
+}
+
+// evalMembership tells whether element is in container: an element of an
+// array, a key of a hash, a substring of a string or an integer of a range.
+func (e *Evaluator) evalMembership(operator token.Token, element, container object.Object) object.Object {
+	switch container := container.(type) {
+	case *object.Array:
+		for _, candidate := range container.Elements {
+			if e.objectsEqual(operator, element, candidate) {
+				return TRUE
+			}
+		}
+		return FALSE
+	case *object.Hash:
+		key, ok := element.(object.Hashable)
+		if !ok {
+			return newError(operator, "unusable as hash key: %s", element.Type())
+		}
+
+		_, ok = container.Get(key)
+		return nativeBoolToBooleanObject(ok)
+	case *object.String:
+		substring, ok := element.(*object.String)
+		if !ok {
+			return newError(operator, "type mismatch: %s in %s", element.Type(), container.Type())
+		}
+
+		return nativeBoolToBooleanObject(strings.Contains(container.Value, substring.Value))
+	case *object.Range:
+		integer, ok := element.(*object.Integer)
+		return nativeBoolToBooleanObject(ok && container.Contains(integer.Value))
+	}
+
+	return newError(operator, "unknown operator: %s in %s", element.Type(), container.Type())
+}
+
+// evalIndex reads the element of left at index, which is null when left has
+// none there.
+func evalIndex(at token.Token, left, index object.Object) object.Object {
+	switch left := left.(type) {
+	case *object.Array:
+		if bounds, ok := index.(*object.Range); ok {
+			return arraySlice(at, left, bounds)
+		}
+
+		integer, ok := index.(*object.Integer)
+		if !ok {
+			return newError(at, "array index must be %s, got %s", object.INTEGER_OBJ, index.Type())
+		}
+
+		if integer.Value < 0 || integer.Value >= int64(len(left.Elements)) {
+			return NULL
+		}
+		return left.Elements[integer.Value]
+	case *object.Hash:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError(at, "unusable as hash key: %s", index.Type())
+		}
+
+		if value, ok := left.Get(key); ok {
+			return value
+		}
+		return NULL
+	}
+
+	return newError(at, "index operator not supported: %s", left.Type())
+}
+
+// ============ helpers ============
+
+// maxBigIntegerBits is the most bits a big integer result of ** or << may have.
+const maxBigIntegerBits = 1 << 24
+
+// exceedsBigIntegerBits tells whether factor * count bits are beyond
+// maxBigIntegerBits, both being positive.
+func exceedsBigIntegerBits(factor, count int64) bool {
+	return factor > 0 && count > maxBigIntegerBits/factor
+}
+
+// multiplyIntegers computes left * right, ok being false on overflow.
+func multiplyIntegers(left, right int64) (product int64, ok bool) {
+	if left == 0 || right == 0 {
+		return 0, true
+	}
+	if (left == -1 && right == math.MinInt64) || (right == -1 && left == math.MinInt64) {
+		return 0, false
+	}
+
+	product = left * right
+	return product, product/right == left
+}
+
+// integerPower computes base ** exponent by squaring, exponent being positive,
+// ok being false on overflow.
+func integerPower(base, exponent int64) (power int64, ok bool) {
+	power = 1
+
+	for exponent > 0 {
+		if exponent&1 == 1 {
+			if power, ok = multiplyIntegers(power, base); !ok {
+				return 0, false
+			}
+		}
+		if exponent >>= 1; exponent > 0 {
+			if base, ok = multiplyIntegers(base, base); !ok {
+				return 0, false
+			}
+		}
+	}
+
+	return power, true
+}
+
+// bigIntegerObject wraps value, as an Integer whenever it fits one.
+func bigIntegerObject(value *big.Int) object.Object {
+	if value.IsInt64() {
+		return &object.Integer{Value: value.Int64()}
+	}
+	return &object.BigInteger{Value: value}
+}
+
+// ratPower computes base ** exponent, base not being zero for a negative
+// exponent.
+func ratPower(base *big.Rat, exponent int64) *big.Rat {
+	if exponent < 0 {
+		base, exponent = new(big.Rat).Inv(base), -exponent
+	}
+
+	power := big.NewInt(exponent)
+	return new(big.Rat).SetFrac(
+		new(big.Int).Exp(base.Num(), power, nil),
+		new(big.Int).Exp(base.Denom(), power, nil),
+	)
+}
+
+// floatPower computes base ** exponent as a float, for both ** and pow. A
+// power that is not a real number or does not fit a float is an error rather
+// than NaN or an infinity.
+func floatPower(at token.Token, base, exponent object.Object) object.Object {
+	x, y := toFloat(base), toFloat(exponent)
+	if x == 0 && y < 0 {
+		return newError(at, "division by zero")
+	}
+	if x < 0 && y != math.Trunc(y) {
+		return newError(at, "fractional power of a negative number: %s ** %s", base.Inspect(), exponent.Inspect())
+	}
+
+	power := math.Pow(x, y)
+	if math.IsInf(power, 0) && !math.IsInf(x, 0) && !math.IsInf(y, 0) {
+		return newError(at, "number too large: %s ** %s", base.Inspect(), exponent.Inspect())
+	}
+
+	return &object.Float{Value: power}
+}
+
+// rationalObject wraps value, as an integer whenever it is a whole number.
+func rationalObject(value *big.Rat) object.Object {
+	if value.IsInt() {
+		return bigIntegerObject(value.Num())
+	}
+	return &object.Rational{Value: value}
+}
+
+// isNumber tells whether obj is an exact number: an integer, a rational or a
+// decimal.
+func isNumber(obj object.Object) bool {
+	return isInteger(obj) || obj.Type() == object.RATIONAL_OBJ || obj.Type() == object.DECIMAL_OBJ
+}
+
+// toFloat converts any number to the nearest float64.
+func toFloat(obj object.Object) float64 {
+	switch number := obj.(type) {
+	case *object.Float:
+		return number.Value
+	case *object.Integer:
+		return float64(number.Value)
+	case *object.BigInteger:
+		value, _ := new(big.Float).SetInt(number.Value).Float64()
+		return value
+	case *object.Rational:
+		value, _ := number.Value.Float64()
+		return value
+	case *object.Decimal:
+		value, _ := number.Rat().Float64()
+		return value
+	}
+	return math.NaN()
+}
+
+// toRat converts any number to an exact fraction.
+func toRat(obj object.Object) *big.Rat {
+	switch number := obj.(type) {
+	case *object.Rational:
+		return number.Value
+	case *object.Decimal:
+		return number.Rat()
+	}
+	return new(big.Rat).SetInt(toBigInt(obj))
+}
+
+// decimalScale is the number of fractional digits of a decimal, 0 for other
+// numbers.
+func decimalScale(obj object.Object) int {
+	if decimal, ok := obj.(*object.Decimal); ok {
+		return decimal.Scale
+	}
+	return 0
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func isInteger(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.BIG_INTEGER_OBJ
+}
+
+// toBigInt converts an Integer or a BigInteger to a big.Int.
+func toBigInt(obj object.Object) *big.Int {
+	if integer, ok := obj.(*object.Integer); ok {
+		return big.NewInt(integer.Value)
+	}
+	return obj.(*object.BigInteger).Value
+}
+
+func nativeBoolToBooleanObject(value bool) *object.Boolean {
+	if value {
+		return TRUE
+	}
+
+	return FALSE
+}
+
+// isTruthy tells whether obj counts as true in conditions: false and null are
+// the only falsy values, so 0, "" and empty collections are all truthy.
+func isTruthy(obj object.Object) bool {
+	if boolean, ok := obj.(*object.Boolean); ok {
+		return boolean.Value
+	}
+
+	return obj != NULL
+}
+
+// interrupts tells whether obj stops the evaluation of the expressions and
+// blocks around it, being an error, a signal for the enclosing loop or a
+// value returned from the enclosing function.
+func interrupts(obj object.Object) bool {
+	if obj == nil {
+		return false
+	}
+
+	switch obj.Type() {
+	case object.ERROR_OBJ, object.BREAK_OBJ, object.CONTINUE_OBJ, object.RETURN_VALUE_OBJ:
+		return true
+	}
+
+	return false
+}
+
+// arrayIndex checks that index is an integer within the bounds of array.
+func arrayIndex(at token.Token, array *object.Array, index object.Object) (int64, *object.Error) {
+	integer, ok := index.(*object.Integer)
+	if !ok {
+		return 0, newError(at, "array index must be %s, got %s", object.INTEGER_OBJ, index.Type())
+	}
+
+	if integer.Value < 0 || integer.Value >= int64(len(array.Elements)) {
+		return 0, newError(at, "index out of range: %d (length %d)", integer.Value, len(array.Elements))
+	}
+
+	return integer.Value, nil
+}
+
+// arraySlice copies the elements of array at the indexes of bounds.
+func arraySlice(at token.Token, array *object.Array, bounds *object.Range) object.Object {
+	start, end := bounds.Start, bounds.End
+	if bounds.Inclusive {
+		end++
+	}
+
+	if start < 0 || end > int64(len(array.Elements)) || start > end {
+		return newError(at, "slice bounds out of range: %s (length %d)", bounds.Inspect(), len(array.Elements))
+	}
+
+	elements := make([]object.Object, end-start)
+	copy(elements, array.Elements[start:end])
+
+	return &object.Array{Elements: elements}
+}
+
+// objectsEqual tells whether left == right holds, values of different types
+// or with no equality defined being unequal.
+func (e *Evaluator) objectsEqual(at token.Token, left, right object.Object) bool {
+	operator := token.Token{Type: token.EQUAL, Literal: token.EQUAL.Lexeme(), Location: at.Location}
+	return e.evalInfixOperation(operator, left, right) == TRUE
+}
+
+// ============ errors ============
+
+func newError(at token.Token, format string, a ...interface{}) *object.Error {
+	return &object.Error{Message: fmt.Sprintf(format, a...), Location: at.Location}
+}
@@ -0,0 +1,1084 @@
+package evaluator
+
+import (
+	"gibbon/lexer"
+	"gibbon/object"
+	"gibbon/parser"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvalIntegerExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"5", 5},
+		{"-10", -10},
+		{"+7", 7},
+		{"5 + 5 + 5 + 5 - 10", 10},
+		{"2 * 2 * 2 * 2 * 2", 32},
+		{"-50 + 100 + -50", 0},
+		{"20 + 2 * -10", 0},
+		{"50 / 2 * 2 + 10", 60},
+		{"3 * 3 * 3 + 10", 37},
+		{"let a = 5; let b = a * 2; a + b", 15},
+		{"7 % 3", 1},
+		{"-7 % 3", -1},
+		{"2 ** 10", 1024},
+		{"2 ** 3 ** 2", 512},
+		{"-2 ** 2", -4},
+		{"5 ** 0", 1},
+		{"2 * 3 ** 2", 18},
+		{"6 & 3", 2},
+		{"6 | 3", 7},
+		{"6 ^ 3", 5},
+		{"1 << 4", 16},
+		{"-16 >> 2", -4},
+		{"~5", -6},
+		{"1 | 2 ^ 6 & 3", 1},
+		{"1 << 1 + 1", 4},
+	}
+
+	for _, test := range tests {
+		testIntegerObject(t, testEval(t, test.input), test.expected)
+	}
+}
+
+func TestEvalBigIntegerExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"9223372036854775807 + 1", "9223372036854775808"},
+		{"-9223372036854775807 - 2", "-9223372036854775809"},
+		{"9223372036854775807 * 2", "18446744073709551614"},
+		{"-9223372036854775807 - 1", -9223372036854775808},
+		{"(-9223372036854775807 - 1) / -1", "9223372036854775808"},
+		{"-(-9223372036854775807 - 1)", "9223372036854775808"},
+		{"2 ** 64", "18446744073709551616"},
+		{"2 ** 63 - 1", 9223372036854775807},
+		{"(-2) ** 63", -9223372036854775808},
+		{"3 ** 40", "12157665459056928801"},
+		{"1 << 64", "18446744073709551616"},
+		{"-1 << 63", -9223372036854775808},
+		{"123456789012345678901234567890", "123456789012345678901234567890"},
+		{"-9223372036854775808", -9223372036854775808},
+		{"9223372036854775808 - 1", 9223372036854775807},
+		{"99999999999999999999 / 10", "9999999999999999999"},
+		{"99999999999999999999 / 100", 999999999999999999},
+		{"-99999999999999999999 % 7", -1},
+		{"(1 << 70) >> 68", 4},
+		{"-(1 << 70) >> 100", -1},
+		{"(1 << 70) >> 100", 0},
+		{"~(1 << 70)", "-1180591620717411303425"},
+		{"(1 << 70) & 3", 0},
+		{"(1 << 70) | 1", "1180591620717411303425"},
+		{"(1 << 70) ^ (1 << 70)", 0},
+		{"+(1 << 70)", "1180591620717411303424"},
+		{"let x = 1; for (i in 1..=25) { x *= i }; x", "15511210043330985984000000"},
+		{"(1 << 70) > 1", true},
+		{"1 >= (1 << 70)", false},
+		{"-(1 << 70) < 0", true},
+		{"(1 << 70) <= (1 << 71)", true},
+		{"(1 << 70) == 2 ** 70", true},
+		{"(1 << 70) != 2 ** 70", false},
+		{"(1 << 70) == 1", false},
+		{"{2 ** 70: 1}[1 << 70]", 1},
+		{"(1 << 70) in [1, 2 ** 70]", true},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(t, test.input)
+
+		switch expected := test.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testBigIntegerObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestOverflowDetection(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+		expectedColumn  uint
+	}{
+		{"9223372036854775807 + 1", "integer overflow: 9223372036854775807 + 1", 21},
+		{"-9223372036854775807 - 2", "integer overflow: -9223372036854775807 - 2", 22},
+		{"let x = 4611686018427387904; x * 2", "integer overflow: 4611686018427387904 * 2", 32},
+		{"let x = 9223372036854775807; x += 1", "integer overflow: 9223372036854775807 + 1", 32},
+		{"let x = -9223372036854775808; -x", "integer overflow: -(-9223372036854775808)", 31},
+		{"-9223372036854775808 / -1", "integer overflow: -9223372036854775808 / -1", 22},
+		{"2 ** 63", "integer overflow: 2 ** 63", 3},
+		{"1 << 63", "integer overflow: 1 << 63", 3},
+		{"9223372036854775808", "integer overflow: 9223372036854775808", 1},
+		{"-9223372036854775809", "integer overflow: -9223372036854775809", 2},
+	}
+
+	for _, test := range tests {
+		e := NewEvaluator()
+		e.DetectOverflow()
+		testErrorObject(t, testEvalWith(t, e, test.input), test.expectedMessage, 1, test.expectedColumn)
+	}
+
+	e := NewEvaluator()
+	e.DetectOverflow()
+	testIntegerObject(t, testEvalWith(t, e, "-9223372036854775808"), math.MinInt64)
+	testIntegerObject(t, testEvalWith(t, e, "-9223372036854775807 - 1"), math.MinInt64)
+	testIntegerObject(t, testEvalWith(t, e, "2 ** 62 + (2 ** 62 - 1)"), math.MaxInt64)
+	testIntegerObject(t, testEvalWith(t, e, "-1 << 63"), math.MinInt64)
+}
+
+func TestEvalDecimalExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"12.50d", "12.50"},
+		{"-0.05d", "-0.05"},
+		{"7d", "7"},
+		{"0.1d + 0.2d", "0.3"},
+		{"0.1d + 0.2d == 0.3d", true},
+		{"12.50d + 1", "13.50"},
+		{"1 - 0.25d", "0.75"},
+		{"12.50d * 1.075d", "13.43750"},
+		{"2 * 0.5d", "1.0"},
+		{"10.00d / 4", "2.50"},
+		{"1d / 3", "0.3333333333333333"},
+		{"2d / 3", "0.6666666666666667"},
+		{"1d / 8", "0.125"},
+		{"7.5d % 2", "1.5"},
+		{"-7.5d % 2", "-1.5"},
+		{"1.5d ** 2", "2.25"},
+		{"2d ** -2", "0.25"},
+		{"+1.5d", "1.5"},
+		{"-(1.5d)", "-1.5"},
+		{"let total = 0d; for (price in [1.10d, 2.20d, 3.30d]) { total += price }; total", "6.60"},
+		{"1 == 1.00d", true},
+		{"1.5d > 1", true},
+		{"1.5d <= 1.49d", false},
+		{"1.50d != 1.5d", false},
+		{"(1 << 70) + 0.5d", "1180591620717411303424.5"},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(t, test.input)
+
+		switch expected := test.expected.(type) {
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			if _, ok := evaluated.(*object.Decimal); assert.Truef(t, ok, "not a decimal for %q, got=%T (%+v)", test.input, evaluated, evaluated) {
+				assert.Equalf(t, expected, evaluated.Inspect(), "wrong result for %q", test.input)
+			}
+		}
+	}
+}
+
+func TestEvalFloatExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"1.5", "1.5"},
+		{"-2.25", "-2.25"},
+		{"0.1 + 0.2", "0.30000000000000004"},
+		{"1.5 + 1", "2.5"},
+		{"1 + 1.0", "2.0"},
+		{"3 * 0.5", "1.5"},
+		{"1 / 4.0", "0.25"},
+		{"7.5 % 2", "1.5"},
+		{"2.0 ** 0.5", "1.4142135623730951"},
+		{"(1 << 70) * 1.0", "1.1805916207174113e+21"},
+		{"+1.5", "1.5"},
+		{"1.5 < 2", true},
+		{"2 >= 2.5", false},
+		{"1 == 1.0", true},
+		{"0.1 + 0.2 != 0.3", true},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(t, test.input)
+
+		switch expected := test.expected.(type) {
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			if _, ok := evaluated.(*object.Float); assert.Truef(t, ok, "not a float for %q, got=%T (%+v)", test.input, evaluated, evaluated) {
+				assert.Equalf(t, expected, evaluated.Inspect(), "wrong result for %q", test.input)
+			}
+		}
+	}
+}
+
+func TestDecimalPrecision(t *testing.T) {
+	tests := []struct {
+		input    string
+		digits   int
+		mode     object.RoundingMode
+		expected string
+	}{
+		{"2d / 3", 2, object.RoundHalfEven, "0.67"},
+		{"2d / 3", 2, object.RoundDown, "0.66"},
+		{"1d / 8", 2, object.RoundHalfEven, "0.12"},
+		{"1d / 8", 2, object.RoundHalfUp, "0.13"},
+		{"-1d / 8", 2, object.RoundFloor, "-0.13"},
+		{"-1d / 8", 2, object.RoundCeiling, "-0.12"},
+		{"1.125d * 2", 1, object.RoundHalfEven, "2.250"},
+		{"10.000d / 3", 1, object.RoundHalfEven, "3.333"},
+	}
+
+	for _, test := range tests {
+		e := NewEvaluator()
+		assert.NoError(t, e.SetDecimalPrecision(test.digits, test.mode))
+		evaluated := testEvalWith(t, e, test.input)
+		if assert.NotNilf(t, evaluated, "no result for %q", test.input) {
+			assert.Equalf(t, test.expected, evaluated.Inspect(), "wrong result for %q", test.input)
+		}
+	}
+}
+
+func TestDecimalPrecisionOutOfRange(t *testing.T) {
+	assert := assert.New(t)
+
+	e := NewEvaluator()
+	assert.EqualError(e.SetDecimalPrecision(-1, object.RoundDown), "decimal precision out of range: -1")
+	assert.EqualError(e.SetDecimalPrecision(maxDecimalPrecision+1, object.RoundDown), "decimal precision out of range: 1001")
+	assert.Equal("0.6666666666666667", testEvalWith(t, e, "2d / 3").Inspect())
+	assert.NoError(e.SetDecimalPrecision(maxDecimalPrecision, object.RoundDown))
+}
+
+func TestExactDivision(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"1 / 3", "1/3"},
+		{"-6 / 4", "-3/2"},
+		{"6 / 3", 2},
+		{"1 / 3 + 1 / 6", "1/2"},
+		{"1 / 3 * 3", 1},
+		{"1 / 3 + 1", "4/3"},
+		{"(1 / 2) ** 2", "1/4"},
+		{"(2 / 3) ** -2", "9/4"},
+		{"(1 / 2) / (1 / 4)", 2},
+		{"7 / 2 % 1", "1/2"},
+		{"1 / 3 * 3.00d", "1.00"},
+		{"1 / 3 + 0d", "0.3333333333333333"},
+		{"let x = 5; x /= 2; x", "5/2"},
+		{"1 / 3 < 1 / 2", true},
+		{"2 / 4 == 1 / 2", true},
+		{"1 / 2 == 0.5d", true},
+		{"-(1 / 3)", "-1/3"},
+		{"(1 << 70) / 3", "1180591620717411303424/3"},
+		{"(1 << 70) / (1 << 69)", 2},
+		{"7 % 2", 1},
+	}
+
+	for _, test := range tests {
+		e := NewEvaluator()
+		e.ExactDivision()
+		evaluated := testEvalWith(t, e, test.input)
+
+		switch expected := test.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			if assert.NotNilf(t, evaluated, "no result for %q", test.input) {
+				assert.Equalf(t, expected, evaluated.Inspect(), "wrong result for %q", test.input)
+			}
+		}
+	}
+
+	testIntegerObject(t, testEval(t, "7 / 2"), 3)
+}
+
+func TestEvalBooleanExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"true", true},
+		{"false", false},
+		{"1 < 2", true},
+		{"1 > 2", false},
+		{"1 <= 1", true},
+		{"2 >= 3", false},
+		{"1 == 1", true},
+		{"1 != 1", false},
+		{"true == true", true},
+		{"true != false", true},
+		{"1 < 2 == false", false},
+		{"!true", false},
+		{"!false", true},
+		{"!5", false},
+	}
+
+	for _, test := range tests {
+		testBooleanObject(t, testEval(t, test.input), test.expected)
+	}
+}
+
+func TestEvalLogicalExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"true && true", true},
+		{"true && false", false},
+		{"false && true", false},
+		{"false || true", true},
+		{"false || false", false},
+		{"true || false", true},
+		{"1 < 2 && 2 < 3", true},
+		{"1 > 2 || 2 > 3", false},
+		{"false || false || true", true},
+		{"true && 0", true},
+		{"let ok = 1 == 1; ok && !false", true},
+	}
+
+	for _, test := range tests {
+		testBooleanObject(t, testEval(t, test.input), test.expected)
+	}
+}
+
+func TestLogicalExpressionShortCircuits(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"false && undefined", false},
+		{"true || undefined", true},
+		{"1 > 2 && 1 / 0", false},
+		{"1 < 2 || 1 / 0", true},
+	}
+
+	for _, test := range tests {
+		testBooleanObject(t, testEval(t, test.input), test.expected)
+	}
+
+	testErrorObject(t, testEval(t, "true && undefined"), "identifier not found: undefined", 1, 9)
+	testErrorObject(t, testEval(t, "false || undefined"), "identifier not found: undefined", 1, 10)
+}
+
+func TestErrorHandling(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+		expectedLine    uint
+		expectedColumn  uint
+	}{
+		{"5 + true;", "type mismatch: INTEGER + BOOLEAN", 1, 3},
+		{"5 + true; 5;", "type mismatch: INTEGER + BOOLEAN", 1, 3},
+		{"-true", "unknown operator: -BOOLEAN", 1, 1},
+		{"true + false;", "unknown operator: BOOLEAN + BOOLEAN", 1, 6},
+		{"5;\ntrue < false; 5", "unknown operator: BOOLEAN < BOOLEAN", 2, 6},
+		{"foobar", "identifier not found: foobar", 1, 1},
+		{"let a = 1;\nlet b = a / 0;", "division by zero", 2, 11},
+		{"5 % 0", "modulo by zero", 1, 3},
+		{"2 ** -1", "negative exponent: -1", 1, 3},
+		{"1 << -1", "negative shift count: -1", 1, 3},
+		{"~true", "unknown operator: ~BOOLEAN", 1, 1},
+		{"true & false", "unknown operator: BOOLEAN & BOOLEAN", 1, 6},
+		{"(1 << 70) / 0", "division by zero", 1, 11},
+		{"(1 << 70) % 0", "modulo by zero", 1, 11},
+		{"2 ** -(1 << 70)", "negative exponent: -1180591620717411303424", 1, 3},
+		{"(1 << 70) << -1", "negative shift count: -1", 1, 11},
+		{"2 ** (1 << 70)", "integer too large: 2 ** 1180591620717411303424", 1, 3},
+		{"1 << 99999999", "integer too large: 1 << 99999999", 1, 3},
+		{"(1 << 70) + true", "type mismatch: BIG_INTEGER + BOOLEAN", 1, 11},
+		{"1.5d / 0", "division by zero", 1, 6},
+		{"1.5d % 0d", "modulo by zero", 1, 6},
+		{"0d ** -1", "division by zero", 1, 4},
+		{"2 ** 0.5d", "exponent must be INTEGER, got DECIMAL", 1, 3},
+		{"1.5d & 1", "unknown operator: DECIMAL & INTEGER", 1, 6},
+		{"~1.5d", "unknown operator: ~DECIMAL", 1, 1},
+		{"1.5d + \"a\"", "type mismatch: DECIMAL + STRING", 1, 6},
+		{"1.5d + 1.5", "type mismatch: DECIMAL + FLOAT", 1, 6},
+		{"1.5 / 0", "division by zero", 1, 5},
+		{"1.5 % 0.0", "modulo by zero", 1, 5},
+		{"(-8.0) ** 0.5", "fractional power of a negative number: -8.0 ** 0.5", 1, 8},
+		{"10.0 ** 400", "number too large: 10.0 ** 400", 1, 6},
+		{"0.0 ** -1", "division by zero", 1, 5},
+		{"1.5 & 1", "unknown operator: FLOAT & INTEGER", 1, 5},
+	}
+
+	for _, test := range tests {
+		testErrorObject(t, testEval(t, test.input), test.expectedMessage, test.expectedLine, test.expectedColumn)
+	}
+}
+
+func TestEvalStringExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"hello world"`, "hello world"},
+		{`"hello" + " " + "world"`, "hello world"},
+		{`"tab\tand \"quotes\""`, "tab\tand \"quotes\""},
+		{`let s = "a"; s += "b"; s`, "ab"},
+	}
+
+	for _, test := range tests {
+		testStringObject(t, testEval(t, test.input), test.expected)
+	}
+
+	testBooleanObject(t, testEval(t, `"a" == "a"`), true)
+	testBooleanObject(t, testEval(t, `"a" != "a"`), false)
+}
+
+func TestEvalArrayAndHashLiterals(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedInspect string
+	}{
+		{`[]`, "[]"},
+		{`[1, 2 * 2, 3 + 3]`, "[1, 4, 6]"},
+		{`[[1], "two", true]`, "[[1], two, true]"},
+		{`{}`, "{}"},
+		{`let two = "two"; {"one": 10 - 9, two: 1 + 1, 3: 3, true: [4]}`, "{one: 1, two: 2, 3: 3, true: [4]}"},
+		{`{"a": 1, "b": 2, "a": 3}`, "{a: 3, b: 2}"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expectedInspect, testEval(t, test.input).Inspect())
+	}
+}
+
+func TestEvalIndexExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{`[1, 2, 3][0]`, 1},
+		{`[1, 2, 3][1 + 1]`, 3},
+		{`let i = 0; [7][i]`, 7},
+		{`let xs = [1, 2, 3]; xs[0] + xs[1] + xs[2]`, 6},
+		{`[[1, 2], [3, 4]][1][0]`, 3},
+		{`{"a": 5}["a"]`, 5},
+		{`let key = "b"; {"a": 5, "b": 6}[key]`, 6},
+		{`{1: 1, true: 2}[true]`, 2},
+	}
+
+	for _, test := range tests {
+		testIntegerObject(t, testEval(t, test.input), test.expected)
+	}
+}
+
+func TestEvalAssignExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{`let x = 1; x = 2; x`, 2},
+		{`let x = 1; x = x + 1; x`, 2},
+		{`let x = 1; x = 5`, 5},
+		{`let x = 1; x += 2; x`, 3},
+		{`let x = 10; x -= 4; x`, 6},
+		{`let x = 3; x *= 4; x`, 12},
+		{`let x = 17; x /= 5; x`, 3},
+		{`let x = 17; x %= 5; x`, 2},
+		{`let x = 1; let y = 1; x = y = 7; x + y`, 14},
+		{`let arr = [1, 2, 3]; arr[1] = 20; arr[1]`, 20},
+		{`let arr = [1, 2, 3]; arr[2] += 10; arr[2]`, 13},
+		{`let m = [[1, 2], [3, 4]]; m[1][0] *= 5; m[1][0]`, 15},
+		{`let h = {"k": 1}; h["k"] = 4; h["k"]`, 4},
+		{`let h = {}; h["new"] = 9; h["new"]`, 9},
+		{`let h = {"n": 1}; h["n"] += 1; h["n"]`, 2},
+		{`let a = [1]; let b = a; b[0] = 2; a[0]`, 2},
+	}
+
+	for _, test := range tests {
+		testIntegerObject(t, testEval(t, test.input), test.expected)
+	}
+}
+
+func TestAssignmentUpdatesEnclosingEnvironment(t *testing.T) {
+	assert := assert.New(t)
+
+	l := lexer.NewLexerFromBytes([]byte(`counter += 1; shadowed = 2;`), "input")
+	program := parser.NewParser(l).ParseProgram()
+
+	outer := object.NewEnvironment()
+	outer.Set("counter", &object.Integer{Value: 1})
+	outer.Set("shadowed", &object.Integer{Value: 1})
+	inner := object.NewEnclosedEnvironment(outer)
+	inner.Set("shadowed", &object.Integer{Value: 1})
+
+	NewEvaluator().Eval(program, inner)
+
+	counter, _ := outer.Get("counter")
+	testIntegerObject(t, counter, 2)
+
+	outerShadowed, _ := outer.Get("shadowed")
+	testIntegerObject(t, outerShadowed, 1)
+
+	innerShadowed, _ := inner.Get("shadowed")
+	testIntegerObject(t, innerShadowed, 2)
+
+	_, declaredInner := inner.Get("counter")
+	assert.True(declaredInner)
+}
+
+func TestCollectionAndAssignmentErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+		expectedLine    uint
+		expectedColumn  uint
+	}{
+		{`x = 1`, "assignment to undeclared identifier: x", 1, 1},
+		{"let y = 1;\nx += y", "assignment to undeclared identifier: x", 2, 1},
+		{`let x = 1; x += "a"`, "type mismatch: INTEGER + STRING", 1, 14},
+		{`"a" - "b"`, "unknown operator: STRING - STRING", 1, 5},
+		{`[1]["0"]`, "array index must be INTEGER, got STRING", 1, 4},
+		{`{"a": 1}[[]]`, "unusable as hash key: ARRAY", 1, 9},
+		{`{[]: 1}`, "unusable as hash key: ARRAY", 1, 1},
+		{`1[0]`, "index operator not supported: INTEGER", 1, 2},
+		{`let a = [1]; a[1] = 2`, "index out of range: 1 (length 1)", 1, 15},
+		{`let a = [1]; a[-1] -= 2`, "index out of range: -1 (length 1)", 1, 15},
+		{`let h = {}; h["k"] += 1`, "type mismatch: NULL + INTEGER", 1, 20},
+		{`let s = "abc"; s[0] = "x"`, "index assignment not supported: STRING", 1, 17},
+	}
+
+	for _, test := range tests {
+		testErrorObject(t, testEval(t, test.input), test.expectedMessage, test.expectedLine, test.expectedColumn)
+	}
+}
+
+func TestEvalConstStatement(t *testing.T) {
+	testIntegerObject(t, testEval(t, `const LIMIT = 5; LIMIT * 2`), 10)
+	testIntegerObject(t, testEval(t, `const ITEMS = [1, 2]; ITEMS[0] = 7; ITEMS[0]`), 7)
+}
+
+func TestConstantsAreEnforcedAtRuntime(t *testing.T) {
+	env := object.NewEnvironment()
+	evaluator := NewEvaluator()
+
+	// every line of the REPL is parsed on its own, so only the environment
+	// knows about constants declared on previous lines
+	inputs := []struct {
+		input           string
+		expectedMessage string
+		expectedLine    uint
+		expectedColumn  uint
+	}{
+		{"const LIMIT = 10;", "", 0, 0},
+		{"LIMIT = 11", "cannot assign to constant LIMIT, declared at 1:7", 1, 1},
+		{"  LIMIT *= 2", "cannot assign to constant LIMIT, declared at 1:7", 1, 3},
+		{"let LIMIT = 1;", "cannot redeclare constant LIMIT, declared at 1:7", 1, 5},
+		{"const LIMIT = 1;", "cannot redeclare constant LIMIT, declared at 1:7", 1, 7},
+	}
+
+	for _, test := range inputs {
+		l := lexer.NewLexerFromBytes([]byte(test.input), "REPL")
+		p := parser.NewParser(l)
+		program := p.ParseProgram()
+		if !assert.Empty(t, p.Errors()) {
+			t.FailNow()
+		}
+
+		result := evaluator.Eval(program, env)
+		if test.expectedMessage == "" {
+			assert.Nil(t, result)
+			continue
+		}
+
+		testErrorObject(t, result, test.expectedMessage, test.expectedLine, test.expectedColumn)
+	}
+
+	limit, _ := env.Get("LIMIT")
+	testIntegerObject(t, limit, 10)
+}
+
+func TestEvalIfExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`if (true) { 10 }`, 10},
+		{`if (false) { 10 }`, nil},
+		{`if (1 < 2) { 10 } else { 20 }`, 10},
+		{`if (1 > 2) { 10 } else { 20 }`, 20},
+		{`if (1) { 10 }`, 10},
+		{`if (if (false) { 1 }) { 10 } else { 20 }`, 20},
+		{`if (true) {}`, nil},
+		{`let x = if (true) { let y = 2; }; x`, nil},
+		{`let x = 1; if (true) { let x = 2; x = 3; }; x`, 1},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(t, test.input)
+
+		if expected, ok := test.expected.(int); ok {
+			testIntegerObject(t, evaluated, int64(expected))
+		} else {
+			assert.Equalf(t, NULL, evaluated, "wrong result for %q", test.input)
+		}
+	}
+}
+
+func TestEvalWhileExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{`let i = 0; while (i < 5) { i += 1 }; i`, 5},
+		{`let i = 0; while (false) { i += 1 }; i`, 0},
+		{`let i = 0; while (true) { i += 1; if (i == 3) { break; } }; i`, 3},
+		{`let i = 0; let sum = 0; while (i < 10) { i += 1; if (i % 2 == 0) { continue; } sum += i; }; sum`, 25},
+		{`let i = 0; let n = 0; while (i < 3) { i += 1; let j = 0; while (true) { j += 1; n += 1; if (j == 2) { break; } } }; n`, 6},
+		{`let i = 0; while (true) { i += 1; let x = if (i < 4) { continue; } else { break; }; i = 100; }; i`, 4},
+		{`let i = 0; while (i < 3) { const step = 1; i += step; }; i`, 3},
+	}
+
+	for _, test := range tests {
+		testIntegerObject(t, testEval(t, test.input), test.expected)
+	}
+
+	assert.Equal(t, NULL, testEval(t, `while (false) {}`))
+}
+
+func TestEvalForInExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`let sum = 0; for (x in [1, 2, 3]) { sum += x }; sum`, 6},
+		{`let sum = 0; for (i, x in [10, 20, 30]) { sum += i * x }; sum`, 80},
+		{`let sum = 0; for (x in []) { sum += 1 }; sum`, 0},
+		{`let s = ""; for (k, v in {"a": 1, "b": 2}) { s += k }; s`, "ab"},
+		{`let sum = 0; for (v in {"a": 1, "b": 2}) { sum += v }; sum`, 3},
+		{`let s = ""; for (c in "héllo") { s = c + s }; s`, "olléh"},
+		{`let last = 0; for (i, c in "日本語") { last = i }; last`, 2},
+		{`let sum = 0; for (x in [1, 2, 3, 4, 5]) { if (x == 2) { continue; } if (x == 4) { break; } sum += x }; sum`, 4},
+		{`let n = 0; for (x in [1, 2]) { for (y in [1, 2, 3]) { if (y == 2) { break; } n += 1 } }; n`, 2},
+		{`let x = 5; for (x in [1, 2]) {}; x`, 5},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(t, test.input)
+
+		switch expected := test.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		}
+	}
+
+	assert.Equal(t, NULL, testEval(t, `for (x in [1]) { x }`))
+	testErrorObject(t, testEval(t, `for (x in 10) {}`), "cannot iterate over INTEGER", 1, 1)
+	testErrorObject(t, testEval(t, `for (x in [1, "a"]) { x + 1 }`), "type mismatch: STRING + INTEGER", 1, 25)
+}
+
+func TestEvalRangeExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`let sum = 0; for (i in 1..5) { sum += i }; sum`, 10},
+		{`let sum = 0; for (i in 1..=5) { sum += i }; sum`, 15},
+		{`let n = 0; for (i in 5..1) { n += 1 }; n`, 0},
+		{`let n = 0; for (i in 3..=3) { n += i }; n`, 3},
+		{`let sum = 0; for (k, v in 10..13) { sum += k }; sum`, 3},
+		{`let n = 0; for (i in 9223372036854775806..=9223372036854775807) { n += 1 }; n`, 2},
+		{`let xs = [1, 2, 3, 4]; let sum = 0; for (x in xs[1..3]) { sum += x }; sum`, 5},
+		{`[1, 2, 3, 4][1..=3][2]`, 4},
+		{`let xs = [1, 2]; let ys = xs[0..2]; ys[0] = 9; xs[0]`, 1},
+		{`[1, 2, 3][1..1]`, "[]"},
+		{`1..10`, "1..10"},
+		{`2 * 3..=10 - 1`, "6..=9"},
+		{`5 in 1..10`, true},
+		{`10 in 1..10`, false},
+		{`10 in 1..=10`, true},
+		{`0 in 1..10`, false},
+		{`"a" in 1..10`, false},
+		{`2 in [1, 2, 3]`, true},
+		{`"2" in [1, 2, 3]`, false},
+		{`[1] in [[1]]`, false},
+		{`"k" in {"k": 1}`, true},
+		{`1 in {"k": 1}`, false},
+		{`"ell" in "hello"`, true},
+		{`"" in "hello"`, true},
+		{`"z" in "hello"`, false},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(t, test.input)
+
+		switch expected := test.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			if assert.NotNilf(t, evaluated, "no result for %q", test.input) {
+				assert.Equalf(t, expected, evaluated.Inspect(), "wrong result for %q", test.input)
+			}
+		}
+	}
+}
+
+func TestRangeErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+		expectedColumn  uint
+	}{
+		{`1.."a"`, "range bounds must be INTEGER, got INTEGER..STRING", 2},
+		{`true..=2`, "range bounds must be INTEGER, got BOOLEAN..=INTEGER", 5},
+		{`[1, 2][1..3]`, "slice bounds out of range: 1..3 (length 2)", 7},
+		{`[1, 2][2..1]`, "slice bounds out of range: 2..1 (length 2)", 7},
+		{`[1, 2][-1..1]`, "slice bounds out of range: -1..1 (length 2)", 7},
+		{`let xs = [1]; xs[0..1] = 2`, "array index must be INTEGER, got RANGE", 17},
+		{`1 in 5`, "unknown operator: INTEGER in INTEGER", 3},
+		{`1 in "one"`, "type mismatch: INTEGER in STRING", 3},
+		{`[] in {}`, "unusable as hash key: ARRAY", 4},
+	}
+
+	for _, test := range tests {
+		testErrorObject(t, testEval(t, test.input), test.expectedMessage, 1, test.expectedColumn)
+	}
+}
+
+func TestEvalNull(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`null`, nil},
+		{`[1, 2][2]`, nil},
+		{`[1, 2][-1]`, nil},
+		{`{"a": 1}["b"]`, nil},
+		{`null == null`, true},
+		{`null != null`, false},
+		{`null == false`, false},
+		{`0 != null`, true},
+		{`[1][5] == null`, true},
+		{`!null`, true},
+		{`if (null) { 1 } else { 2 }`, 2},
+		{`if (0) { 1 } else { 2 }`, 1},
+		{`if ("") { 1 } else { 2 }`, 1},
+		{`if ([]) { 1 } else { 2 }`, 1},
+		{`null || false`, false},
+		{`null ?? 5`, 5},
+		{`3 ?? 5`, 3},
+		{`false ?? 5`, false},
+		{`null ?? null ?? 7`, 7},
+		{`null ?? null`, nil},
+		{`let h = {"a": {"b": [10, 20]}}; h?.a?.b?[1]`, 20},
+		{`let h = {"a": 1}; h?.missing`, nil},
+		{`let h = null; h?.a`, nil},
+		{`let xs = null; xs?[0]`, nil},
+		{`let h = null; h?.a[0]["c"]`, nil},
+		{`let h = {}; h?.a ?? "default"`, "default"},
+		{`let xs = [1, null]; xs[1]?[0]`, nil},
+		{`let h = null; h?.f(1)`, nil},
+		{`let h = null; h?.a["f"](1)[0]`, nil},
+		{`let h = null; h?.f(1)(2)`, nil},
+		{`let n = 0; let h = null; h?.f(n += 1); n`, 0},
+		{`let h = {"f": fn(x) { x + 1 }}; h?.f(1)`, 2},
+		{`let h = {"f": fn() { null }}; h["f"]()?.a`, nil},
+		{`let h = null; 1 |> h?.f(2)`, nil},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(t, test.input)
+
+		switch expected := test.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testStringObject(t, evaluated, expected)
+		default:
+			assert.Equalf(t, NULL, evaluated, "wrong result for %q", test.input)
+		}
+	}
+}
+
+func TestNullCoalescingShortCircuits(t *testing.T) {
+	evaluated := testEval(t, `let calls = 0; let a = 1 ?? if (true) { calls = 1 }; calls`)
+	testIntegerObject(t, evaluated, 0)
+}
+
+func TestNullErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+		expectedColumn  uint
+	}{
+		{`null + 1`, "type mismatch: NULL + INTEGER", 6},
+		{`-null`, "unknown operator: -NULL", 1},
+		{`null[0]`, "index operator not supported: NULL", 5},
+		{`let h = {"a": null}; h?.a[0]`, "index operator not supported: NULL", 26},
+		{`let h = {"a": 1}; h?.a?.b`, "index operator not supported: INTEGER", 23},
+		{`let h = {}; h?.f(1)`, "not a function: NULL", 17},
+		{`{null: 1}`, "unusable as hash key: NULL", 1},
+	}
+
+	for _, test := range tests {
+		testErrorObject(t, testEval(t, test.input), test.expectedMessage, 1, test.expectedColumn)
+	}
+}
+
+func TestEvalInterpolatedString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let name = "gibbon"; let count = 2; "hello ${name}, you have ${count + 1} items"`, "hello gibbon, you have 3 items"},
+		{`"${1}${2}"`, "12"},
+		{`"${true} ${null} ${[1, "a"]} ${{"k": 2}} ${1..3}"`, "true null [1, a] {k: 2} 1..3"},
+		{`let xs = [1, 2]; "first: ${xs[0]}, missing: ${xs[5] ?? "none"}"`, "first: 1, missing: none"},
+		{`"outer ${"inner ${1 + 1}"}"`, "outer inner 2"},
+		{`"costs \${price}"`, "costs ${price}"},
+		{`let s = ""; for (i in 1..=3) { s += "${i}," }; s`, "1,2,3,"},
+	}
+
+	for _, test := range tests {
+		testStringObject(t, testEval(t, test.input), test.expected)
+	}
+
+	testErrorObject(t, testEval(t, `"a ${1 + "b"} c"`), "type mismatch: INTEGER + STRING", 1, 8)
+}
+
+func TestEvalFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`let identity = fn(x) { x; }; identity(5);`, 5},
+		{`let identity = fn(x) { return x; }; identity(5);`, 5},
+		{`let double = fn(x) { x * 2; }; double(5);`, 10},
+		{`let add = fn(x, y) { x + y; }; add(5 + 5, add(5, 5));`, 20},
+		{`fn(x) { x; }(5)`, 5},
+		{`let f = fn() { return 1; 2 }; f()`, 1},
+		{`let f = fn() { if (true) { if (true) { return 10; } } return 1; }; f()`, 10},
+		{`let f = fn() { let i = 0; while (true) { i += 1; if (i == 3) { return i; } } }; f()`, 3},
+		{`let f = fn() { for (x in [1, 2, 3]) { if (x == 2) { return x * 10; } } }; f()`, 20},
+		{`let fact = fn(n) { if (n < 2) { return 1; } n * fact(n - 1) }; fact(10)`, 3628800},
+		{`let adder = fn(x) { fn(y) { x + y } }; let addTwo = adder(2); addTwo(3)`, 5},
+		{`let counter = fn() { let n = 0; fn() { n += 1 } }; let next = counter(); next(); next()`, 2},
+		{`let x = 1; let f = fn(x) { x = 5; x }; f(2) + x`, 6},
+		{`let f = fn() { let y = 1; }; f()`, nil},
+		{`let f = fn() { return; }; f()`, nil},
+		{`let f = fn() {}; f()`, nil},
+		{`return 7; 8`, 7},
+		{`(1 + 2) * 3`, 9},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(t, test.input)
+
+		if expected, ok := test.expected.(int); ok {
+			testIntegerObject(t, evaluated, int64(expected))
+		} else {
+			assert.Equalf(t, NULL, evaluated, "wrong result for %q", test.input)
+		}
+	}
+}
+
+func TestEvalPipeExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`let double = fn(x) { x * 2 }; 3 |> double`, 6},
+		{`let sub = fn(a, b) { a - b }; 10 |> sub(3)`, 7},
+		{`let double = fn(x) { x * 2 }; let add = fn(a, b) { a + b }; 3 |> double |> add(1) |> double`, 14},
+		{`1 + 2 |> fn(x) { x * 10 }`, 30},
+		{`let wrap = fn(s, l, r) { l + s + r }; "x" |> wrap("<", ">")`, "<x>"},
+		{`let pick = fn(h) { h?.k ?? 0 }; {"k": 4} |> pick`, 4},
+		{`let adder = fn(n) { fn(x) { x + n } }; 2 |> (adder(10))`, 12},
+		{`let adder = fn(n) { fn(x) { x + n } }; 2 |> ((adder(10))) |> (adder(1))`, 13},
+		{`let sub = fn(a, b) { a - b }; 10 |> (sub)(3)`, 7},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(t, test.input)
+
+		switch expected := test.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestFunctionErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+		expectedColumn  uint
+	}{
+		{`let f = fn(x) { x }; f()`, "wrong number of arguments: want=1, got=0", 23},
+		{`let f = fn(x) { x }; f(1, 2)`, "wrong number of arguments: want=1, got=2", 23},
+		{`5(1)`, "not a function: INTEGER", 2},
+		{`1 |> 2`, "not a function: INTEGER", 3},
+		{`let f = fn() { 1 }; 1 |> f`, "wrong number of arguments: want=0, got=1", 23},
+		{`let f = fn() { 1 }; 1 |> f()`, "wrong number of arguments: want=0, got=1", 27},
+		{`let f = fn(x) { x + true }; f(1)`, "type mismatch: INTEGER + BOOLEAN", 19},
+		{`let f = fn(x) { x }; f(1 + "a")`, "type mismatch: INTEGER + STRING", 26},
+		{`let f = fn() { y }; f()`, "identifier not found: y", 16},
+		{`let f = fn(n) { f(n + 1) }; f(0)`, "maximum call depth exceeded: 10000", 18},
+		{`let f = fn(n) { map([n], f) }; f(0)`, "maximum call depth exceeded: 10000", 20},
+	}
+
+	for _, test := range tests {
+		testErrorObject(t, testEval(t, test.input), test.expectedMessage, 1, test.expectedColumn)
+	}
+}
+
+func TestCallDepthIsRestoredAfterErrors(t *testing.T) {
+	e := NewEvaluator()
+	testEvalWith(t, e, `let f = fn(n) { f(n + 1) }; f(0)`)
+
+	testIntegerObject(t, testEvalWith(t, e, `let count = fn(n) { if (n == 0) { 0 } else { 1 + count(n - 1) } }; count(9000)`), 9000)
+}
+
+func TestLoopErrorsStopTheLoop(t *testing.T) {
+	evaluated := testEval(t, `let i = 0;
+while (true) {
+  i += 1;
+  if (i == 3) { i + "a"; }
+}`)
+
+	testErrorObject(t, evaluated, "type mismatch: INTEGER + STRING", 4, 19)
+}
+
+// ------HELPERS------
+
+// resultTest is an input and what it evaluates to, as checked by testResults.
+type resultTest struct {
+	input    string
+	expected interface{}
+}
+
+// source is an expected result written as a program, for results whose
+// inspection is ambiguous, like arrays of strings.
+type source string
+
+// testResults evaluates each input with e. An int or a bool is compared to the
+// result's value, nil to NULL, a source to what it evaluates to and a string to
+// the result's inspection.
+func testResults(t *testing.T, e *Evaluator, tests []resultTest) {
+	for _, test := range tests {
+		evaluated := testEvalWith(t, e, test.input)
+
+		switch expected := test.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case nil:
+			assert.Equalf(t, NULL, evaluated, "wrong result for %q", test.input)
+		case source:
+			assert.Equalf(t, testEval(t, string(expected)), evaluated, "wrong result for %q", test.input)
+		case string:
+			if assert.NotNilf(t, evaluated, "no result for %q", test.input) {
+				assert.Equalf(t, expected, evaluated.Inspect(), "wrong result for %q", test.input)
+			}
+		default:
+			t.Fatalf("unsupported expected result %T for %q", expected, test.input)
+		}
+	}
+}
+
+// errorTest is a one line input and the error it evaluates to.
+type errorTest struct {
+	input           string
+	expectedMessage string
+	expectedColumn  uint
+}
+
+func testErrors(t *testing.T, tests []errorTest) {
+	for _, test := range tests {
+		testErrorObject(t, testEval(t, test.input), test.expectedMessage, 1, test.expectedColumn)
+	}
+}
+
+func testEval(t *testing.T, input string) object.Object {
+	return testEvalWith(t, NewEvaluator(), input)
+}
+
+func testEvalWith(t *testing.T, e *Evaluator, input string) object.Object {
+	l := lexer.NewLexerFromBytes([]byte(input), "input")
+	p := parser.NewParser(l)
+	program := p.ParseProgram()
+
+	if !assert.Emptyf(t, p.Errors(), "parser errors for %q", input) {
+		t.FailNow()
+	}
+
+	return e.Eval(program, object.NewEnvironment())
+}
+
+func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
+	assert := assert.New(t)
+
+	integer, ok := obj.(*object.Integer)
+	if !assert.Truef(ok, "object is not *object.Integer, got=%T (%+v)", obj, obj) {
+		return false
+	}
+
+	return assert.Equal(expected, integer.Value)
+}
+
+func testBigIntegerObject(t *testing.T, obj object.Object, expected string) bool {
+	assert := assert.New(t)
+
+	integer, ok := obj.(*object.BigInteger)
+	if !assert.Truef(ok, "object is not *object.BigInteger, got=%T (%+v)", obj, obj) {
+		return false
+	}
+
+	return assert.Equal(expected, integer.Value.String())
+}
+
+func testBooleanObject(t *testing.T, obj object.Object, expected bool) bool {
+	assert := assert.New(t)
+
+	boolean, ok := obj.(*object.Boolean)
+	if !assert.Truef(ok, "object is not *object.Boolean, got=%T (%+v)", obj, obj) {
+		return false
+	}
+
+	return assert.Equal(expected, boolean.Value)
+}
+
+func testStringObject(t *testing.T, obj object.Object, expected string) bool {
+	assert := assert.New(t)
+
+	str, ok := obj.(*object.String)
+	if !assert.Truef(ok, "object is not *object.String, got=%T (%+v)", obj, obj) {
+		return false
+	}
+
+	return assert.Equal(expected, str.Value)
+}
+
+func testErrorObject(t *testing.T, obj object.Object, expectedMessage string, expectedLine, expectedColumn uint) bool {
+	assert := assert.New(t)
+
+	err, ok := obj.(*object.Error)
+	if !assert.Truef(ok, "object is not *object.Error, got=%T (%+v)", obj, obj) {
+		return false
+	}
+
+	return assert.Equal(expectedMessage, err.Message) &&
+		assert.Equal(expectedLine, err.Location.Line) &&
+		assert.Equal(expectedColumn, err.Location.FirstCharIndex)
+}
@@ -1,8 +1,52 @@
+// Package lexer splits gibbon source code into tokens, following this
+// lexical grammar:
+//
+//	whitespace   = " " | "\t" | "\r" | "\n" .
+//	letter       = "a" … "z" | "A" … "Z" | "_" .
+//	digit        = "0" … "9" .
+//	identifier   = letter { letter | digit } [ "?" | "!" ] .
+//	integer      = digit { digit } .
+//	float        = digit { digit } "." digit { digit } .
+//	decimal      = digit { digit } [ "." digit { digit } ] "d" .
+//	string       = `"` { character | escape } `"` .
+//	escape       = `\` character .
+//	interpolated = stringHead expression { stringMiddle expression } stringTail .
+//	stringHead   = `"` { character | escape } "${" .
+//	stringMiddle = "}" { character | escape } "${" .
+//	stringTail   = "}" { character | escape } `"` .
+//	keyword      = "fn" | "return" | "let" | "const" | "if" | "else" | "while" |
+//	               "break" | "continue" | "for" | "in" | "true" | "false" | "null" .
+//	operator     = "=" | "+" | "-" | "!" | "*" | "/" | "<" | ">" | "<=" | ">=" |
+//	               "==" | "!=" | "&&" | "||" | "%" | "**" | "&" | "|" | "^" | "<<" |
+//	               ">>" | "~" | "+=" | "-=" | "*=" | "/=" | "%=" | ".." | "..=" |
+//	               "??" | "?." | "?[" | "|>" .
+//	delimiter    = "," | ";" | "(" | ")" | "{" | "}" | "[" | "]" | ":" .
+//
+// String literals keep their quotes and escapes as written; telling which
+// escapes are valid is up to the parser. A string missing its closing quote
+// is an ILLEGAL token running to the end of the source.
+//
+// A "${" in a string starts an embedded expression, lexed as usual tokens up
+// to the "}" matching it, after which the string goes on. Such a string is
+// split into STRING_HEAD, STRING_MIDDLE and STRING_TAIL tokens around the
+// tokens of its expressions; the "${" and "}" belong to the string parts.
+//
+// A UTF-8 byte order mark and a "#!" shebang line at the very start of the
+// source are skipped, so scripts can be made executable. A number is only a
+// decimal when it ends with its "d" suffix, so that "1do" reads as an integer
+// followed by an identifier, and only has a fraction when a digit follows its
+// ".", so that "1..2" still reads as a range. Keywords are the identifiers
+// spelled like one. An identifier only takes a trailing "?" or "!" when it is
+// not followed by "=", so that "x!=y" still reads as a comparison, and a
+// trailing "?" when it is not followed by "?", "." or "[" either, so that
+// "x??y", "x?.y" and "x?[0]" read as null-safe operations. Any other
+// character is an ILLEGAL token.
 package lexer
 
 import (
 	"gibbon/token"
 	"io"
+	"strings"
 )
 
 const EOF_CHAR = 0
@@ -13,44 +57,107 @@ type bytePosition struct {
 }
 
 type Lexer struct {
-	input               io.ByteReader // input being parsed
-	fileName            string        // name of the file being lexed
-	currentChar         byte          // current char in examination
-	currentCharPosition bytePosition  // current byte position on file being parsed
-	nextCharPosition    bytePosition  // next byte position on file being parsed
-	eofReached          bool          // indicates wether has been completely read
+	input               string            // source being lexed, sliced to build token literals
+	fileName            string            // name of the file being lexed
+	offset              int               // offset of currentChar in input
+	readOffset          int               // offset of the next char to be read from input
+	currentChar         byte              // current char in examination
+	currentCharPosition bytePosition      // current byte position on file being parsed
+	nextCharPosition    bytePosition      // next byte position on file being parsed
+	identifiers         map[string]string // interned identifiers, nil unless interning is enabled
+	preserveTrivia      bool              // whether tokens carry the source skipped around them
+	lastTokenEnd        int               // offset right after the last token and its trailing trivia
+	interpolations      []int             // open "{" count of each enclosing embedded expression, innermost last
 }
 
+const byteOrderMark = "\xEF\xBB\xBF"
+
 func NewLexer(input io.ByteReader, fileName string) *Lexer {
+	return NewLexerFromBytes(readAllBytes(input), fileName)
+}
+
+// NewLexerFromBytes lexes input directly, without going through an
+// io.ByteReader. Token literals are substrings of a single copy of input, so
+// lexing does not allocate per token.
+func NewLexerFromBytes(input []byte, fileName string) *Lexer {
 	initialPosition := bytePosition{line: 1, byte: 1}
 
 	l := &Lexer{
-		input:               input,
+		input:               string(input),
 		fileName:            fileName,
 		currentCharPosition: initialPosition,
 		nextCharPosition:    initialPosition,
 	}
 
+	// the byte order mark is not shown by editors, so it takes no column
+	if strings.HasPrefix(l.input, byteOrderMark) {
+		l.readOffset = len(byteOrderMark)
+	}
+
 	l.readChar()
+	l.skipShebang()
 	return l
 }
 
+func (l *Lexer) skipShebang() {
+	if l.currentChar != '#' || l.peekChar() != '!' {
+		return
+	}
+
+	for l.currentChar != '\n' && l.currentChar != EOF_CHAR {
+		l.readChar()
+	}
+}
+
+func readAllBytes(input io.ByteReader) []byte {
+	if reader, ok := input.(io.Reader); ok {
+		bytes, _ := io.ReadAll(reader)
+		return bytes
+	}
+
+	bytes := []byte{}
+	for b, err := input.ReadByte(); err == nil; b, err = input.ReadByte() {
+		bytes = append(bytes, b)
+	}
+
+	return bytes
+}
+
+// InternIdentifiers makes the lexer return a single shared copy of every
+// distinct identifier literal, so long-lived ASTs do not keep the whole
+// source alive and repeated names share memory.
+func (l *Lexer) InternIdentifiers() {
+	if l.identifiers == nil {
+		l.identifiers = make(map[string]string)
+	}
+}
+
+// PreserveTrivia makes the lexer attach the source it skips, such as
+// whitespace or a shebang line, to the tokens around it: a token's trailing
+// trivia runs up to and including the end of its line, and everything else
+// before a token is its leading trivia. Concatenating every token's trivia and
+// literal, up to EOF, reproduces the source byte-for-byte.
+func (l *Lexer) PreserveTrivia() {
+	l.preserveTrivia = true
+}
+
 func (l *Lexer) SourceFile() string {
 	return l.fileName
 }
 
 func (l *Lexer) readChar() {
-	byte, err := l.input.ReadByte()
+	l.currentCharPosition = l.nextCharPosition
+	l.offset = l.readOffset
 
-	l.currentChar = byte
+	if l.readOffset >= len(l.input) {
+		l.currentChar = EOF_CHAR
+		return
+	}
 
-	l.currentCharPosition.line = l.nextCharPosition.line
-	l.currentCharPosition.byte = l.nextCharPosition.byte
+	l.currentChar = l.input[l.readOffset]
+	l.readOffset++
 
-	if err == io.EOF {
-		l.currentCharPosition = l.nextCharPosition
-		return
-	} else if byte == '\n' {
+	if l.currentChar == '\n' {
 		l.nextCharPosition.line++
 		l.nextCharPosition.byte = 1
 	} else {
@@ -59,33 +166,98 @@ func (l *Lexer) readChar() {
 }
 
 func (l *Lexer) NextToken() token.Token {
+	if !l.preserveTrivia {
+		return l.scanToken()
+	}
+
+	l.skipWhitespace()
+	leadingTrivia := l.input[l.lastTokenEnd:l.offset]
+
+	nextToken := l.scanToken()
+	nextToken.LeadingTrivia = leadingTrivia
+	if nextToken.Type != token.EOF {
+		nextToken.TrailingTrivia = l.readTrailingTrivia()
+	}
+	l.lastTokenEnd = l.offset
+
+	return nextToken
+}
+
+func (l *Lexer) scanToken() token.Token {
 	var nextToken token.Token
 
 	l.skipWhitespace()
 	switch l.currentChar {
 	// Operators
 	case '+':
-		nextToken = newToken(token.PLUS, l.currentChar, l.currentCharPosition)
+		nextToken = l.newCompoundAssignmentToken(token.PLUS, token.PLUS_ASSIGN)
 	case '-':
-		nextToken = newToken(token.MINUS, l.currentChar, l.currentCharPosition)
+		nextToken = l.newCompoundAssignmentToken(token.MINUS, token.MINUS_ASSIGN)
 	case '*':
-		nextToken = newToken(token.ASTERISK, l.currentChar, l.currentCharPosition)
+		if l.peekChar() == '*' {
+			nextToken = l.newDoubledCharToken(token.ASTERISK, token.POWER)
+		} else {
+			nextToken = l.newCompoundAssignmentToken(token.ASTERISK, token.ASTERISK_ASSIGN)
+		}
 	case '/':
-		nextToken = newToken(token.SLASH, l.currentChar, l.currentCharPosition)
+		nextToken = l.newCompoundAssignmentToken(token.SLASH, token.SLASH_ASSIGN)
+	case '%':
+		nextToken = l.newCompoundAssignmentToken(token.PERCENT, token.PERCENT_ASSIGN)
+	case '&':
+		nextToken = l.newDoubledCharToken(token.BIT_AND, token.AND)
+	case '|':
+		if l.peekChar() == '>' {
+			nextToken = l.newTwoCharToken(token.PIPE)
+		} else {
+			nextToken = l.newDoubledCharToken(token.BIT_OR, token.OR)
+		}
+	case '^':
+		nextToken = l.newToken(token.BIT_XOR)
+	case '~':
+		nextToken = l.newToken(token.TILDE)
+	case '.':
+		nextToken = l.newRangeToken()
+	case '?':
+		nextToken = l.newNullSafeToken()
 
 		// Delimiters
 	case ',':
-		nextToken = newToken(token.COMMA, l.currentChar, l.currentCharPosition)
+		nextToken = l.newToken(token.COMMA)
 	case ';':
-		nextToken = newToken(token.SEMICOLON, l.currentChar, l.currentCharPosition)
+		nextToken = l.newToken(token.SEMICOLON)
 	case '(':
-		nextToken = newToken(token.LPAREN, l.currentChar, l.currentCharPosition)
+		nextToken = l.newToken(token.LPAREN)
 	case ')':
-		nextToken = newToken(token.RPAREN, l.currentChar, l.currentCharPosition)
+		nextToken = l.newToken(token.RPAREN)
 	case '{':
-		nextToken = newToken(token.LBRACE, l.currentChar, l.currentCharPosition)
+		if depth := len(l.interpolations); depth > 0 {
+			l.interpolations[depth-1]++
+		}
+		nextToken = l.newToken(token.LBRACE)
 	case '}':
-		nextToken = newToken(token.RBRACE, l.currentChar, l.currentCharPosition)
+		if depth := len(l.interpolations); depth > 0 {
+			if l.interpolations[depth-1] == 0 {
+				// this closes an embedded expression, the string goes on
+				l.interpolations = l.interpolations[:depth-1]
+				nextToken.Location = token.TokenLocation{Line: l.currentCharPosition.line, FirstCharIndex: l.currentCharPosition.byte}
+				nextToken.Type, nextToken.Literal = l.readString()
+				return nextToken
+			}
+			l.interpolations[depth-1]--
+		}
+		nextToken = l.newToken(token.RBRACE)
+	case '[':
+		nextToken = l.newToken(token.LBRACKET)
+	case ']':
+		nextToken = l.newToken(token.RBRACKET)
+	case ':':
+		nextToken = l.newToken(token.COLON)
+
+		// Literals
+	case '"':
+		nextToken.Location = token.TokenLocation{Line: l.currentCharPosition.line, FirstCharIndex: l.currentCharPosition.byte}
+		nextToken.Type, nextToken.Literal = l.readString()
+		return nextToken
 
 		// Special
 	case EOF_CHAR:
@@ -98,18 +270,20 @@ func (l *Lexer) NextToken() token.Token {
 			nextToken.Literal = l.readMultiCharToken(isOperator)
 			nextToken.Type = token.GetOperatorTokenType(nextToken.Literal)
 			return nextToken
-		} else if isValidInIdentifier(l.currentChar) {
+		} else if isIdentifierStart(l.currentChar) {
 			nextToken.Location = token.TokenLocation{Line: l.currentCharPosition.line, FirstCharIndex: l.currentCharPosition.byte}
-			nextToken.Literal = l.readMultiCharToken(isValidInIdentifier)
+			nextToken.Literal = l.readIdentifier()
 			nextToken.Type = token.GetIdentTokenType(nextToken.Literal)
+			if nextToken.Type == token.IDENT {
+				nextToken.Literal = l.intern(nextToken.Literal)
+			}
 			return nextToken
 		} else if isDigit(l.currentChar) {
 			nextToken.Location = token.TokenLocation{Line: l.currentCharPosition.line, FirstCharIndex: l.currentCharPosition.byte}
-			nextToken.Type = token.INT
-			nextToken.Literal = l.readMultiCharToken(isDigit)
+			nextToken.Type, nextToken.Literal = l.readNumber()
 			return nextToken
 		} else {
-			nextToken = newToken(token.ILLEGAL, l.currentChar, l.currentCharPosition)
+			nextToken = l.newToken(token.ILLEGAL)
 		}
 	}
 
@@ -118,10 +292,22 @@ func (l *Lexer) NextToken() token.Token {
 	return nextToken
 }
 
-func isValidInIdentifier(char byte) bool {
+func isIdentifierStart(char byte) bool {
 	return 'a' <= char && char <= 'z' || 'A' <= char && char <= 'Z' || char == '_'
 }
 
+func isValidInIdentifier(char byte) bool {
+	return isIdentifierStart(char) || isDigit(char)
+}
+
+func isIdentifierSuffix(char byte) bool {
+	return char == '?' || char == '!'
+}
+
+func isNullSafeOperatorSecondChar(char byte) bool {
+	return char == '?' || char == '.' || char == '['
+}
+
 func isDigit(char byte) bool {
 	return '0' <= char && char <= '9'
 }
@@ -132,11 +318,25 @@ func (l *Lexer) skipWhitespace() {
 	}
 }
 
+func (l *Lexer) readTrailingTrivia() string {
+	start := l.offset
+
+	for l.currentChar == ' ' || l.currentChar == '\t' || l.currentChar == '\r' {
+		l.readChar()
+	}
+
+	if l.currentChar == '\n' {
+		l.readChar()
+	}
+
+	return l.input[start:l.offset]
+}
+
 var multiCharOperatorsInitials = [...]byte{
-	token.LTE[0],
-	token.GTE[0],
-	token.EQUAL[0],
-	token.DIFFERENT[0],
+	token.LTE.Lexeme()[0],
+	token.GTE.Lexeme()[0],
+	token.EQUAL.Lexeme()[0],
+	token.DIFFERENT.Lexeme()[0],
 }
 
 func isOperator(char byte) bool {
@@ -150,23 +350,182 @@ func isOperator(char byte) bool {
 }
 
 func (l *Lexer) readMultiCharToken(verifierFunc func(byte) bool) string {
-	readChars := []byte{}
+	start := l.offset
 
 	for verifierFunc(l.currentChar) {
-		readChars = append(readChars, l.currentChar)
 		l.readChar()
 	}
 
-	return string(readChars)
+	return l.input[start:l.offset]
+}
+
+// readNumber reads an integer or a float, or a decimal when the digits are
+// followed by an optional fraction and a "d" suffix.
+func (l *Lexer) readNumber() (token.TokenType, string) {
+	start := l.offset
+	l.readMultiCharToken(isDigit)
+
+	numberType, end := token.INT, l.offset
+	if end+1 < len(l.input) && l.input[end] == '.' && isDigit(l.input[end+1]) {
+		numberType = token.FLOAT
+		for end++; end < len(l.input) && isDigit(l.input[end]); end++ {
+		}
+	}
+
+	if end < len(l.input) && l.input[end] == 'd' &&
+		(end+1 >= len(l.input) || !isValidInIdentifier(l.input[end+1])) {
+		numberType, end = token.DECIMAL, end+1
+	}
+
+	for l.offset < end {
+		l.readChar()
+	}
+
+	return numberType, l.input[start:l.offset]
+}
+
+func (l *Lexer) readIdentifier() string {
+	start := l.offset
+
+	for isValidInIdentifier(l.currentChar) {
+		l.readChar()
+	}
+
+	if isIdentifierSuffix(l.currentChar) && l.peekChar() != '=' &&
+		(l.currentChar != '?' || !isNullSafeOperatorSecondChar(l.peekChar())) {
+		l.readChar()
+	}
+
+	return l.input[start:l.offset]
+}
+
+func (l *Lexer) peekChar() byte {
+	if l.readOffset >= len(l.input) {
+		return EOF_CHAR
+	}
+
+	return l.input[l.readOffset]
+}
+
+func (l *Lexer) intern(identifier string) string {
+	if l.identifiers == nil {
+		return identifier
+	}
+
+	if interned, ok := l.identifiers[identifier]; ok {
+		return interned
+	}
+
+	interned := strings.Clone(identifier)
+	l.identifiers[interned] = interned
+	return interned
+}
+
+// readString reads a string literal, quotes included, returning an ILLEGAL
+// token type when the source ends before its closing quote. Reading from the
+// "}" closing an embedded expression, it reads the rest of the string instead.
+// Strings with embedded expressions stop right after each "${".
+func (l *Lexer) readString() (token.TokenType, string) {
+	start := l.offset
+	resumed := l.currentChar == '}'
+	l.readChar()
+
+	for l.currentChar != '"' {
+		if l.offset >= len(l.input) {
+			return token.ILLEGAL, l.input[start:]
+		}
+
+		if l.currentChar == '\\' {
+			l.readChar()
+		} else if l.currentChar == '$' && l.peekChar() == '{' {
+			l.readChar()
+			l.readChar()
+			l.interpolations = append(l.interpolations, 0)
+
+			if resumed {
+				return token.STRING_MIDDLE, l.input[start:l.offset]
+			}
+			return token.STRING_HEAD, l.input[start:l.offset]
+		}
+		l.readChar()
+	}
+
+	l.readChar()
+
+	if resumed {
+		return token.STRING_TAIL, l.input[start:l.offset]
+	}
+	return token.STRING, l.input[start:l.offset]
+}
+
+// newCompoundAssignmentToken reads an assignmentType token when the current
+// char is followed by "=", as in "+=", or an operatorType one otherwise.
+func (l *Lexer) newCompoundAssignmentToken(operatorType, assignmentType token.TokenType) token.Token {
+	if l.peekChar() != '=' {
+		return l.newToken(operatorType)
+	}
+
+	return l.newTwoCharToken(assignmentType)
+}
+
+// newDoubledCharToken reads a doubledType token when the current char is
+// repeated, as in "&&", or a singleType one for the current char otherwise.
+func (l *Lexer) newDoubledCharToken(singleType, doubledType token.TokenType) token.Token {
+	if l.peekChar() != l.currentChar {
+		return l.newToken(singleType)
+	}
+
+	return l.newTwoCharToken(doubledType)
+}
+
+// newRangeToken reads a ".." or a "..=" token, or an ILLEGAL one for a lone
+// ".".
+func (l *Lexer) newRangeToken() token.Token {
+	if l.peekChar() != '.' {
+		return l.newToken(token.ILLEGAL)
+	}
+
+	nextToken := l.newTwoCharToken(token.RANGE)
+
+	if l.peekChar() == '=' {
+		l.readChar()
+		nextToken.Type = token.RANGE_INCLUSIVE
+		nextToken.Literal = l.input[l.offset-2 : l.offset+1]
+	}
+
+	return nextToken
+}
+
+// newNullSafeToken reads a "??", "?." or "?[" token, or an ILLEGAL one for a
+// lone "?".
+func (l *Lexer) newNullSafeToken() token.Token {
+	switch l.peekChar() {
+	case '?':
+		return l.newTwoCharToken(token.NULL_COALESCE)
+	case '.':
+		return l.newTwoCharToken(token.OPTIONAL_DOT)
+	case '[':
+		return l.newTwoCharToken(token.OPTIONAL_LBRACKET)
+	}
+
+	return l.newToken(token.ILLEGAL)
+}
+
+// newTwoCharToken reads a token spelled as the current and the next chars.
+func (l *Lexer) newTwoCharToken(tokenType token.TokenType) token.Token {
+	nextToken := l.newToken(tokenType)
+	l.readChar()
+	nextToken.Literal = l.input[l.offset-1 : l.offset+1]
+	return nextToken
 }
 
-func newToken(tokenType token.TokenType, tokenChar byte, bytePosition bytePosition) token.Token {
+func (l *Lexer) newToken(tokenType token.TokenType) token.Token {
 	return token.Token{
 		Type:    tokenType,
-		Literal: string(tokenChar),
+		Literal: l.input[l.offset : l.offset+1],
 		Location: token.TokenLocation{
-			Line:           bytePosition.line,
-			FirstCharIndex: bytePosition.byte,
+			Line:           l.currentCharPosition.line,
+			FirstCharIndex: l.currentCharPosition.byte,
 		},
 	}
 }
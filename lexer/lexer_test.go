@@ -5,6 +5,7 @@ import (
 	"gibbon/token"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"unsafe"
 )
 
 func TestNextTokenWithBaseTokens(t *testing.T) {
@@ -164,3 +165,320 @@ func TestEOFDetection(t *testing.T) {
 	NewLexer(input, "filename")
 
 }
+
+// lexicalGrammar holds, for every token type, inputs lexed as a single token
+// of that type whose literal is the whole input, once the context of the type
+// in lexicalContexts has been lexed.
+var lexicalGrammar = map[token.TokenType][]string{
+	token.ILLEGAL:           {"@", "'", "<>", "=!", "?", "<<=", ">>>", `"unterminated`, `"escaped quote\"`, `"`, "."},
+	token.EOF:               {"", " \t\r\n"},
+	token.IDENT:             {"x", "_", "snake_case", "camelCase", "x1", "user2", "a1b2c3", "_9", "empty?", "save!"},
+	token.INT:               {"0", "7", "1234567890", "007"},
+	token.FLOAT:             {"1.5", "0.0", "3.14159", "007.700"},
+	token.DECIMAL:           {"12.50d", "0.1d", "7d", "007.700d"},
+	token.STRING:            {`""`, `"hello"`, `"with spaces and ; = symbols"`, `"escaped \" quote"`, `"\\"`, "\"multi\nline\"", `"ünïcödé"`},
+	token.STRING_HEAD:       {`"${`, `"total: ${`, `"\${ ${`},
+	token.STRING_MIDDLE:     {`}${`, `}, and ${`},
+	token.STRING_TAIL:       {`}"`, `} items"`, `} \" \${"`},
+	token.ASSIGN:            {"="},
+	token.PLUS:              {"+"},
+	token.MINUS:             {"-"},
+	token.BANG:              {"!"},
+	token.ASTERISK:          {"*"},
+	token.SLASH:             {"/"},
+	token.LT:                {"<"},
+	token.GT:                {">"},
+	token.LTE:               {"<="},
+	token.GTE:               {">="},
+	token.EQUAL:             {"=="},
+	token.DIFFERENT:         {"!="},
+	token.AND:               {"&&"},
+	token.OR:                {"||"},
+	token.PERCENT:           {"%"},
+	token.POWER:             {"**"},
+	token.BIT_AND:           {"&"},
+	token.BIT_OR:            {"|"},
+	token.BIT_XOR:           {"^"},
+	token.SHIFT_LEFT:        {"<<"},
+	token.SHIFT_RIGHT:       {">>"},
+	token.TILDE:             {"~"},
+	token.PLUS_ASSIGN:       {"+="},
+	token.MINUS_ASSIGN:      {"-="},
+	token.ASTERISK_ASSIGN:   {"*="},
+	token.SLASH_ASSIGN:      {"/="},
+	token.PERCENT_ASSIGN:    {"%="},
+	token.RANGE:             {".."},
+	token.RANGE_INCLUSIVE:   {"..="},
+	token.NULL_COALESCE:     {"??"},
+	token.OPTIONAL_DOT:      {"?."},
+	token.OPTIONAL_LBRACKET: {"?["},
+	token.PIPE:              {"|>"},
+	token.COMMA:             {","},
+	token.SEMICOLON:         {";"},
+	token.LPAREN:            {"("},
+	token.RPAREN:            {")"},
+	token.LBRACE:            {"{"},
+	token.RBRACE:            {"}"},
+	token.LBRACKET:          {"["},
+	token.RBRACKET:          {"]"},
+	token.COLON:             {":"},
+	token.FUNCTION:          {"fn"},
+	token.RETURN:            {"return"},
+	token.LET:               {"let"},
+	token.CONST:             {"const"},
+	token.IF:                {"if"},
+	token.ELSE:              {"else"},
+	token.WHILE:             {"while"},
+	token.BREAK:             {"break"},
+	token.CONTINUE:          {"continue"},
+	token.FOR:               {"for"},
+	token.IN:                {"in"},
+	token.TRUE:              {"true"},
+	token.FALSE:             {"false"},
+	token.NULL:              {"null"},
+}
+
+// lexicalContexts holds, for each token type that can only follow other
+// tokens, the source lexed before it.
+var lexicalContexts = map[token.TokenType]string{
+	token.STRING_MIDDLE: `"${x`,
+	token.STRING_TAIL:   `"${x`,
+}
+
+func TestLexicalGrammar(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, tokenType := range token.TokenTypes() {
+		inputs, ok := lexicalGrammar[tokenType]
+		if !assert.Truef(ok, "token type %s is missing from the lexical grammar table", tokenType) {
+			continue
+		}
+
+		for _, input := range inputs {
+			context := lexicalContexts[tokenType]
+			l := NewLexerFromBytes([]byte(context+input), "filename")
+			skipContextTokens(l, context)
+			tok := l.NextToken()
+
+			assert.Equalf(tokenType, tok.Type, "wrong type for %q", input)
+			if tokenType != token.EOF {
+				assert.Equalf(input, tok.Literal, "wrong literal for %q", input)
+				assert.Equalf(token.EOF, l.NextToken().Type, "%q was not lexed as a single token", input)
+			}
+		}
+	}
+}
+
+// skipContextTokens reads as many tokens from l as lexing context gives.
+func skipContextTokens(l *Lexer, context string) {
+	contextLexer := NewLexerFromBytes([]byte(context), "filename")
+
+	for tok := contextLexer.NextToken(); tok.Type != token.EOF; tok = contextLexer.NextToken() {
+		l.NextToken()
+	}
+}
+
+func TestIdentifierBoundaries(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedLiterals []string
+	}{
+		{"let user2 = 1;", []string{"let", "user2", "=", "1", ";"}},
+		{"1x", []string{"1", "x"}},
+		{"x!=y", []string{"x", "!=", "y"}},
+		{"x?==y", []string{"x", "?", "==", "y"}},
+		{"ok!!", []string{"ok!", "!"}},
+		{"valid?(x)", []string{"valid?", "(", "x", ")"}},
+		{"!done", []string{"!", "done"}},
+		{"fn2 letter iffy", []string{"fn2", "letter", "iffy"}},
+		{"a&&!b||c", []string{"a", "&&", "!", "b", "||", "c"}},
+		{"&&&", []string{"&&", "&"}},
+		{"2***~x", []string{"2", "**", "*", "~", "x"}},
+		{"a|b||c^d", []string{"a", "|", "b", "||", "c", "^", "d"}},
+		{"a|>f||>g", []string{"a", "|>", "f", "||", ">", "g"}},
+		{"1<<2>>3%4", []string{"1", "<<", "2", ">>", "3", "%", "4"}},
+		{"x+=1;y**=2", []string{"x", "+=", "1", ";", "y", "**", "=", "2"}},
+		{"x=-1", []string{"x", "=", "-", "1"}},
+		{`h["k"]=v`, []string{"h", "[", `"k"`, "]", "=", "v"}},
+		{`{"a":1}`, []string{"{", `"a"`, ":", "1", "}"}},
+		{`"a""b"`, []string{`"a"`, `"b"`}},
+		{"1..3", []string{"1", "..", "3"}},
+		{"1.5d..2d", []string{"1.5d", "..", "2d"}},
+		{"1.d", []string{"1", ".", "d"}},
+		{"1.5..2.5", []string{"1.5", "..", "2.5"}},
+		{"1.5.2", []string{"1.5", ".", "2"}},
+		{"2do 1.5dx 3d_", []string{"2", "do", "1.5", "dx", "3", "d_"}},
+		{"2d+1d", []string{"2d", "+", "1d"}},
+		{"0..=n-1", []string{"0", "..=", "n", "-", "1"}},
+		{"a...b", []string{"a", "..", ".", "b"}},
+		{"a..==b", []string{"a", "..=", "=", "b"}},
+		{"x??y", []string{"x", "??", "y"}},
+		{"x?.y?[0]", []string{"x", "?.", "y", "?[", "0", "]"}},
+		{"empty?.size", []string{"empty", "?.", "size"}},
+		{"empty?? x", []string{"empty", "??", "x"}},
+		{"empty??? x", []string{"empty", "??", "?", "x"}},
+		{"empty? ?? x", []string{"empty?", "??", "x"}},
+		{"x ? y", []string{"x", "?", "y"}},
+		{`"a ${b} c"`, []string{`"a ${`, "b", `} c"`}},
+		{`"${a}${b}"`, []string{`"${`, "a", `}${`, "b", `}"`}},
+		{`"${ {"k": "}"}["k"] }"`, []string{`"${`, "{", `"k"`, ":", `"}"`, "}", "[", `"k"`, "]", `}"`}},
+		{`"a ${"b ${c}"} d"`, []string{`"a ${`, `"b ${`, "c", `}"`, `} d"`}},
+		{`"$a $ {b} \${c}"`, []string{`"$a $ {b} \${c}"`}},
+		{`{"${x}"}`, []string{"{", `"${`, "x", `}"`, "}"}},
+		{`"${x`, []string{`"${`, "x"}},
+		{`"${x} rest`, []string{`"${`, "x", `} rest`}},
+	}
+
+	for _, test := range tests {
+		l := NewLexerFromBytes([]byte(test.input), "filename")
+		literals := []string{}
+
+		for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+			literals = append(literals, tok.Literal)
+		}
+
+		assert.Equalf(t, test.expectedLiterals, literals, "wrong tokens for %q", test.input)
+	}
+}
+
+func TestSkipsShebangAndByteOrderMark(t *testing.T) {
+	tests := []struct {
+		input                     string
+		expectedType              token.TokenType
+		expectedLiteral           string
+		expectedLocationLine      uint
+		expectedLocationFirstChar uint
+	}{
+		{"#!/usr/bin/env gibbon\nlet", token.LET, "let", 2, 1},
+		{"#!/usr/bin/env gibbon\r\n\n  x", token.IDENT, "x", 3, 3},
+		{"#!/usr/bin/env gibbon", token.EOF, "", 1, 22},
+		{"\xEF\xBB\xBFlet", token.LET, "let", 1, 1},
+		{"\xEF\xBB\xBF#!/usr/bin/env gibbon\n5", token.INT, "5", 2, 1},
+		{" #!/usr/bin/env gibbon", token.ILLEGAL, "#", 1, 2},
+		{"#", token.ILLEGAL, "#", 1, 1},
+		{"x\n#!", token.IDENT, "x", 1, 1},
+	}
+
+	for _, test := range tests {
+		assert := assert.New(t)
+		tok := NewLexerFromBytes([]byte(test.input), "filename").NextToken()
+
+		assert.Equalf(test.expectedType, tok.Type, "wrong type for %q", test.input)
+		assert.Equalf(test.expectedLiteral, tok.Literal, "wrong literal for %q", test.input)
+		assert.Equalf(test.expectedLocationLine, tok.Location.Line, "wrong line for %q", test.input)
+		assert.Equalf(test.expectedLocationFirstChar, tok.Location.FirstCharIndex, "wrong column for %q", test.input)
+	}
+}
+
+func TestPreserveTrivia(t *testing.T) {
+	assert := assert.New(t)
+
+	l := NewLexerFromBytes([]byte("  let x = 1; \t\n\n  x\n"), "filename")
+	l.PreserveTrivia()
+
+	tests := []struct {
+		expectedLiteral        string
+		expectedLeadingTrivia  string
+		expectedTrailingTrivia string
+	}{
+		{"let", "  ", " "},
+		{"x", "", " "},
+		{"=", "", " "},
+		{"1", "", ""},
+		{";", "", " \t\n"},
+		{"x", "\n  ", "\n"},
+		{"", "", ""},
+	}
+
+	for i, test := range tests {
+		token := l.NextToken()
+
+		assert.Equalf(test.expectedLiteral, token.Literal, "Failed on test line %d", i)
+		assert.Equalf(test.expectedLeadingTrivia, token.LeadingTrivia, "Failed on test line %d", i)
+		assert.Equalf(test.expectedTrailingTrivia, token.TrailingTrivia, "Failed on test line %d", i)
+	}
+}
+
+func TestInternIdentifiers(t *testing.T) {
+	assert := assert.New(t)
+
+	l := NewLexerFromBytes([]byte(`total + total`), "filename")
+	l.InternIdentifiers()
+
+	first := l.NextToken()
+	l.NextToken()
+	second := l.NextToken()
+
+	assert.Equal("total", first.Literal)
+	assert.Equal("total", second.Literal)
+	assert.Same(unsafe.StringData(first.Literal), unsafe.StringData(second.Literal))
+}
+
+func TestNextTokenAllocations(t *testing.T) {
+	input := generateScript(200)
+	tokens := 0
+	for l := NewLexerFromBytes(input, "filename"); l.NextToken().Type != token.EOF; {
+		tokens++
+	}
+
+	allocs := testing.AllocsPerRun(10, func() {
+		l := NewLexerFromBytes(input, "filename")
+		for l.NextToken().Type != token.EOF {
+		}
+	})
+
+	assert.Lessf(t, allocs/float64(tokens), 0.01, "%.0f allocations for %d tokens", allocs, tokens)
+}
+
+func BenchmarkNextToken(b *testing.B) {
+	input := generateScript(1000)
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		l := NewLexerFromBytes(input, "filename")
+		for l.NextToken().Type != token.EOF {
+		}
+	}
+}
+
+func BenchmarkNextTokenWithInterning(b *testing.B) {
+	input := generateScript(1000)
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		l := NewLexerFromBytes(input, "filename")
+		l.InternIdentifiers()
+		for l.NextToken().Type != token.EOF {
+		}
+	}
+}
+
+func BenchmarkNextTokenFromByteReader(b *testing.B) {
+	input := generateScript(1000)
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		l := NewLexer(bytes.NewReader(input), "filename")
+		for l.NextToken().Type != token.EOF {
+		}
+	}
+}
+
+// ------HELPERS------
+
+func generateScript(statements int) []byte {
+	var script bytes.Buffer
+
+	for i := 0; i < statements; i++ {
+		script.WriteString("let total = price * quantity + 1250 - discount;\n")
+		script.WriteString("if (!(total >= limit)) { return total != 0; }\n")
+	}
+
+	return script.Bytes()
+}
@@ -2,12 +2,20 @@ package main
 
 import (
 	"fmt"
+	"gibbon/evaluator"
+	"gibbon/lexer"
+	"gibbon/object"
+	"gibbon/parser"
 	"gibbon/repl"
 	"os"
 	"os/user"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		os.Exit(runFile(os.Args[1]))
+	}
+
 	user, err := user.Current()
 
 	if err != nil {
@@ -17,3 +25,28 @@ func main() {
 	fmt.Printf("Welcome to the gibbon interpreter %s!\n", user.Username)
 	repl.Start(os.Stdin, os.Stdout)
 }
+
+func runFile(fileName string) int {
+	source, err := os.ReadFile(fileName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	p := parser.NewParser(lexer.NewLexerFromBytes(source, fileName))
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		repl.PrintParserErrors(os.Stderr, fileName, p.Errors())
+		return 1
+	}
+
+	result := evaluator.NewEvaluator().Eval(program, object.NewEnvironment())
+	if runtimeError, ok := result.(*object.Error); ok {
+		location := runtimeError.Location
+		fmt.Fprintf(os.Stderr, "%s:%d:%d: %s\n", fileName, location.Line, location.FirstCharIndex, runtimeError.Message)
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,77 @@
+package object
+
+import "gibbon/token"
+
+type Environment struct {
+	store     map[string]Object
+	constants map[string]token.TokenLocation // where the constants of store were declared
+	outer     *Environment
+}
+
+func NewEnvironment() *Environment {
+	return &Environment{store: make(map[string]Object), constants: make(map[string]token.TokenLocation)}
+}
+
+func NewEnclosedEnvironment(outer *Environment) *Environment {
+	env := NewEnvironment()
+	env.outer = outer
+	return env
+}
+
+// Get looks name up in this environment, then in the enclosing ones.
+func (e *Environment) Get(name string) (Object, bool) {
+	obj, ok := e.store[name]
+
+	if !ok && e.outer != nil {
+		return e.outer.Get(name)
+	}
+
+	return obj, ok
+}
+
+// Set binds name in this environment, shadowing any enclosing binding.
+func (e *Environment) Set(name string, value Object) Object {
+	e.store[name] = value
+	delete(e.constants, name)
+	return value
+}
+
+// SetConstant binds name like Set, marking the binding as a constant
+// declared at location.
+func (e *Environment) SetConstant(name string, value Object, location token.TokenLocation) Object {
+	e.store[name] = value
+	e.constants[name] = location
+	return value
+}
+
+// Declares tells whether name is bound in this environment itself.
+func (e *Environment) Declares(name string) bool {
+	_, ok := e.store[name]
+	return ok
+}
+
+// Constant tells whether the nearest binding of name is a constant, and
+// where it was declared.
+func (e *Environment) Constant(name string) (token.TokenLocation, bool) {
+	for env := e; env != nil; env = env.outer {
+		if _, ok := env.store[name]; ok {
+			location, constant := env.constants[name]
+			return location, constant
+		}
+	}
+
+	return token.TokenLocation{}, false
+}
+
+// Assign rebinds name in the nearest environment declaring it, reporting
+// whether any does. It does not check whether that binding is a constant.
+func (e *Environment) Assign(name string, value Object) bool {
+	for env := e; env != nil; env = env.outer {
+		if _, ok := env.store[name]; ok {
+			env.store[name] = value
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,112 @@
+package object
+
+import "unicode/utf8"
+
+// Iterable objects can be looped over by for-in expressions.
+type Iterable interface {
+	Object
+	Iterator() Iterator
+}
+
+// Iterator walks the elements of an Iterable, in order.
+type Iterator interface {
+	// Next returns the next element, ok being false once there are none left.
+	// key is the position of value in the iterable, such as its index in an
+	// array or its key in a hash.
+	Next() (key, value Object, ok bool)
+}
+
+func (a *Array) Iterator() Iterator { return &arrayIterator{array: a} }
+
+type arrayIterator struct {
+	array *Array
+	index int
+}
+
+// Next reads the elements array holds when it is called, so elements pushed
+// to the array by the loop body are visited too.
+func (it *arrayIterator) Next() (Object, Object, bool) {
+	if it.index >= len(it.array.Elements) {
+		return nil, nil, false
+	}
+
+	key := &Integer{Value: int64(it.index)}
+	value := it.array.Elements[it.index]
+	it.index++
+
+	return key, value, true
+}
+
+func (h *Hash) Iterator() Iterator { return &hashIterator{hash: h} }
+
+type hashIterator struct {
+	hash  *Hash
+	index int
+}
+
+func (it *hashIterator) Next() (Object, Object, bool) {
+	if it.index >= len(it.hash.Keys) {
+		return nil, nil, false
+	}
+
+	pair := it.hash.Pairs[it.hash.Keys[it.index]]
+	it.index++
+
+	return pair.Key, pair.Value, true
+}
+
+func (r *Range) Iterator() Iterator {
+	last, ok := r.Last()
+	return &rangeIterator{next: r.Start, last: last, done: !ok}
+}
+
+type rangeIterator struct {
+	next  int64
+	last  int64
+	index int64
+	done  bool
+}
+
+func (it *rangeIterator) Next() (Object, Object, bool) {
+	if it.done {
+		return nil, nil, false
+	}
+
+	key := &Integer{Value: it.index}
+	value := &Integer{Value: it.next}
+
+	// stopping at last rather than past it keeps ranges ending at the
+	// largest integer from overflowing
+	if it.next == it.last {
+		it.done = true
+	} else {
+		it.next++
+		it.index++
+	}
+
+	return key, value, true
+}
+
+// Iterator walks the characters of s, keyed by their index in characters
+// rather than in bytes.
+func (s *String) Iterator() Iterator { return &stringIterator{value: s.Value} }
+
+type stringIterator struct {
+	value  string
+	offset int // offset of the next character in bytes
+	index  int // index of the next character in characters
+}
+
+func (it *stringIterator) Next() (Object, Object, bool) {
+	if it.offset >= len(it.value) {
+		return nil, nil, false
+	}
+
+	_, size := utf8.DecodeRuneInString(it.value[it.offset:])
+	key := &Integer{Value: int64(it.index)}
+	value := &String{Value: it.value[it.offset : it.offset+size]}
+	it.offset += size
+	it.index++
+
+	return key, value, true
+}
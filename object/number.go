@@ -0,0 +1,121 @@
+package object
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Float is an IEEE 754 double precision number.
+type Float struct {
+	Value float64
+}
+
+func (f *Float) Type() ObjectType { return FLOAT_OBJ }
+
+// Inspect writes f with the fewest digits that read back as f, and at least
+// one fractional digit when it is whole, so that it cannot be mistaken for
+// an integer.
+func (f *Float) Inspect() string {
+	written := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if strings.ContainsAny(written, ".eIN") {
+		return written
+	}
+	return written + ".0"
+}
+
+// Rational is an exact fraction, such as the result of dividing integers
+// when exact division is enabled. Operations give back an integer whenever
+// their result is a whole number.
+type Rational struct {
+	Value *big.Rat
+}
+
+func (r *Rational) Type() ObjectType { return RATIONAL_OBJ }
+func (r *Rational) Inspect() string  { return r.Value.RatString() }
+
+// Decimal is the exact decimal number Unscaled / 10**Scale. Its scale is the
+// number of fractional digits it was written or computed with, so 12.50d
+// keeps its trailing zero.
+type Decimal struct {
+	Unscaled *big.Int
+	Scale    int
+}
+
+func (d *Decimal) Type() ObjectType { return DECIMAL_OBJ }
+
+func (d *Decimal) Inspect() string {
+	digits := new(big.Int).Abs(d.Unscaled).String()
+	if len(digits) <= d.Scale {
+		digits = strings.Repeat("0", d.Scale-len(digits)+1) + digits
+	}
+
+	var out strings.Builder
+	if d.Unscaled.Sign() < 0 {
+		out.WriteByte('-')
+	}
+	out.WriteString(digits[:len(digits)-d.Scale])
+	if d.Scale > 0 {
+		out.WriteByte('.')
+		out.WriteString(digits[len(digits)-d.Scale:])
+	}
+
+	return out.String()
+}
+
+// Rat returns the exact value of d.
+func (d *Decimal) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(d.Unscaled, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d.Scale)), nil))
+}
+
+// RoundingMode tells how a value is rounded to the digits a decimal keeps.
+type RoundingMode uint8
+
+const (
+	RoundHalfEven RoundingMode = iota // to the nearest, ties to an even last digit
+	RoundHalfUp                       // to the nearest, ties away from zero
+	RoundHalfDown                     // to the nearest, ties towards zero
+	RoundUp                           // away from zero
+	RoundDown                         // towards zero
+	RoundCeiling                      // towards positive infinity
+	RoundFloor                        // towards negative infinity
+)
+
+// NewDecimal rounds value to a decimal of scale fractional digits, scale
+// being positive.
+func NewDecimal(value *big.Rat, scale int, mode RoundingMode) *Decimal {
+	scaled := new(big.Rat).Mul(value, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)))
+	quotient, remainder := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+
+	if remainder.Sign() != 0 && roundsAwayFromZero(mode, value.Sign(), quotient, remainder, scaled.Denom()) {
+		quotient.Add(quotient, big.NewInt(int64(value.Sign())))
+	}
+
+	return &Decimal{Unscaled: quotient, Scale: scale}
+}
+
+// roundsAwayFromZero tells whether the truncated quotient of a division of
+// the given sign is to be moved away from zero, remainder being non-zero.
+func roundsAwayFromZero(mode RoundingMode, sign int, quotient, remainder, divisor *big.Int) bool {
+	// compares the remainder to half the divisor
+	half := new(big.Int).Abs(remainder)
+	half.Lsh(half, 1)
+	tie := half.Cmp(divisor)
+
+	switch mode {
+	case RoundHalfEven:
+		return tie > 0 || tie == 0 && quotient.Bit(0) == 1
+	case RoundHalfUp:
+		return tie >= 0
+	case RoundHalfDown:
+		return tie > 0
+	case RoundUp:
+		return true
+	case RoundCeiling:
+		return sign > 0
+	case RoundFloor:
+		return sign < 0
+	}
+
+	return false
+}
@@ -0,0 +1,296 @@
+package object
+
+import (
+	"fmt"
+	"gibbon/ast"
+	"gibbon/token"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+type ObjectType string
+
+const (
+	INTEGER_OBJ     = "INTEGER"
+	BIG_INTEGER_OBJ = "BIG_INTEGER"
+	FLOAT_OBJ       = "FLOAT"
+	RATIONAL_OBJ    = "RATIONAL"
+	DECIMAL_OBJ     = "DECIMAL"
+	BOOLEAN_OBJ     = "BOOLEAN"
+	STRING_OBJ      = "STRING"
+	ARRAY_OBJ       = "ARRAY"
+	HASH_OBJ        = "HASH"
+	ERROR_OBJ       = "ERROR"
+	NULL_OBJ        = "NULL"
+	RANGE_OBJ       = "RANGE"
+	FUNCTION_OBJ    = "FUNCTION"
+	BUILTIN_OBJ     = "BUILTIN"
+	REGEX_OBJ       = "REGEX"
+	TIME_OBJ        = "TIME"
+	DURATION_OBJ    = "DURATION"
+
+	BREAK_OBJ        = "BREAK"
+	CONTINUE_OBJ     = "CONTINUE"
+	RETURN_VALUE_OBJ = "RETURN_VALUE"
+)
+
+type Object interface {
+	Type() ObjectType
+	Inspect() string
+}
+
+type Integer struct {
+	Value int64
+}
+
+func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
+func (i *Integer) Inspect() string  { return fmt.Sprintf("%d", i.Value) }
+
+// BigInteger is an integer beyond the range of Integer. Operations give back
+// an Integer whenever their result fits one, so the two never hold the same
+// value.
+type BigInteger struct {
+	Value *big.Int
+}
+
+func (bi *BigInteger) Type() ObjectType { return BIG_INTEGER_OBJ }
+func (bi *BigInteger) Inspect() string  { return bi.Value.String() }
+
+type Boolean struct {
+	Value bool
+}
+
+func (b *Boolean) Type() ObjectType { return BOOLEAN_OBJ }
+func (b *Boolean) Inspect() string  { return fmt.Sprintf("%t", b.Value) }
+
+type String struct {
+	Value string
+}
+
+func (s *String) Type() ObjectType { return STRING_OBJ }
+func (s *String) Inspect() string  { return s.Value }
+
+type Array struct {
+	Elements []Object
+}
+
+func (a *Array) Type() ObjectType { return ARRAY_OBJ }
+func (a *Array) Inspect() string  { return inspectContainer(a, map[Object]bool{}) }
+
+// Range is the sequence of integers from Start up to End, End included only
+// when Inclusive. Its integers are computed as they are needed, never stored.
+type Range struct {
+	Start     int64
+	End       int64
+	Inclusive bool
+}
+
+func (r *Range) Type() ObjectType { return RANGE_OBJ }
+func (r *Range) Inspect() string {
+	if r.Inclusive {
+		return fmt.Sprintf("%d..=%d", r.Start, r.End)
+	}
+
+	return fmt.Sprintf("%d..%d", r.Start, r.End)
+}
+
+// Last returns the last integer of r, ok being false when r is empty.
+func (r *Range) Last() (last int64, ok bool) {
+	if r.Inclusive {
+		return r.End, r.Start <= r.End
+	}
+
+	return r.End - 1, r.Start < r.End
+}
+
+func (r *Range) Contains(n int64) bool {
+	last, ok := r.Last()
+	return ok && r.Start <= n && n <= last
+}
+
+// Function is a function literal closed over the environment it was
+// evaluated in.
+type Function struct {
+	Parameters []*ast.Identifier
+	Body       *ast.BlockStatement
+	Env        *Environment
+}
+
+func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
+func (f *Function) Inspect() string {
+	parameters := []string{}
+	for _, parameter := range f.Parameters {
+		parameters = append(parameters, parameter.String())
+	}
+
+	return "fn(" + strings.Join(parameters, ", ") + ") " + f.Body.String()
+}
+
+// BuiltinFunction implements a builtin, at being the call site errors are
+// located at.
+type BuiltinFunction func(at token.Token, arguments ...Object) Object
+
+// Builtin is a function provided by the interpreter rather than written in
+// gibbon, such as len.
+type Builtin struct {
+	Name string
+	Fn   BuiltinFunction
+}
+
+func (b *Builtin) Type() ObjectType { return BUILTIN_OBJ }
+func (b *Builtin) Inspect() string  { return "builtin " + b.Name }
+
+// Regex is a compiled regular expression, in the syntax of Go's regexp
+// package.
+type Regex struct {
+	Value *regexp.Regexp
+}
+
+func (r *Regex) Type() ObjectType { return REGEX_OBJ }
+func (r *Regex) Inspect() string  { return "/" + r.Value.String() + "/" }
+
+// ReturnValue carries the value of a return statement up to the function
+// call it returns from.
+type ReturnValue struct {
+	Value Object
+}
+
+func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
+func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
+
+// Null is the value of the null literal and of expressions that produce no
+// value, such as loops, if expressions whose condition fails without an else
+// branch and lookups of missing indexes or keys.
+type Null struct{}
+
+func (n *Null) Type() ObjectType { return NULL_OBJ }
+func (n *Null) Inspect() string  { return "null" }
+
+// Break is the signal a break statement sends to the enclosing loop.
+type Break struct{}
+
+func (b *Break) Type() ObjectType { return BREAK_OBJ }
+func (b *Break) Inspect() string  { return "break" }
+
+// Continue is the signal a continue statement sends to the enclosing loop.
+type Continue struct{}
+
+func (c *Continue) Type() ObjectType { return CONTINUE_OBJ }
+func (c *Continue) Inspect() string  { return "continue" }
+
+// HashKey identifies a hashable object used as a key of a Hash. Integers and
+// booleans fit Value, whereas strings and big integers are written out in
+// Text, so that distinct keys never share a HashKey.
+type HashKey struct {
+	Type  ObjectType
+	Value uint64
+	Text  string
+}
+
+// Hashable objects can be used as keys of a Hash.
+type Hashable interface {
+	Object
+	HashKey() HashKey
+}
+
+func (i *Integer) HashKey() HashKey {
+	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
+}
+
+func (b *Boolean) HashKey() HashKey {
+	if b.Value {
+		return HashKey{Type: b.Type(), Value: 1}
+	}
+
+	return HashKey{Type: b.Type(), Value: 0}
+}
+
+func (bi *BigInteger) HashKey() HashKey {
+	return HashKey{Type: bi.Type(), Text: bi.Value.String()}
+}
+
+func (s *String) HashKey() HashKey {
+	return HashKey{Type: s.Type(), Text: s.Value}
+}
+
+type HashPair struct {
+	Key   Object
+	Value Object
+}
+
+// Hash maps hashable keys to values, remembering the order keys were added in.
+type Hash struct {
+	Pairs map[HashKey]HashPair
+	Keys  []HashKey // in insertion order
+}
+
+func NewHash() *Hash {
+	return &Hash{Pairs: make(map[HashKey]HashPair)}
+}
+
+func (h *Hash) Get(key Hashable) (Object, bool) {
+	pair, ok := h.Pairs[key.HashKey()]
+	return pair.Value, ok
+}
+
+func (h *Hash) Set(key Hashable, value Object) {
+	hashKey := key.HashKey()
+
+	if _, ok := h.Pairs[hashKey]; !ok {
+		h.Keys = append(h.Keys, hashKey)
+	}
+
+	h.Pairs[hashKey] = HashPair{Key: key, Value: value}
+}
+
+func (h *Hash) Type() ObjectType { return HASH_OBJ }
+func (h *Hash) Inspect() string  { return inspectContainer(h, map[Object]bool{}) }
+
+// inspectContainer writes obj, writing the arrays and hashes it contains in
+// turn. An array or a hash within itself, visiting being those it is within,
+// is written "[...]" or "{...}".
+func inspectContainer(obj Object, visiting map[Object]bool) string {
+	switch obj := obj.(type) {
+	case *Array:
+		if visiting[obj] {
+			return "[...]"
+		}
+		visiting[obj] = true
+		defer delete(visiting, obj)
+
+		elements := []string{}
+		for _, element := range obj.Elements {
+			elements = append(elements, inspectContainer(element, visiting))
+		}
+
+		return "[" + strings.Join(elements, ", ") + "]"
+	case *Hash:
+		if visiting[obj] {
+			return "{...}"
+		}
+		visiting[obj] = true
+		defer delete(visiting, obj)
+
+		pairs := []string{}
+		for _, key := range obj.Keys {
+			pair := obj.Pairs[key]
+			pairs = append(pairs, pair.Key.Inspect()+": "+inspectContainer(pair.Value, visiting))
+		}
+
+		return "{" + strings.Join(pairs, ", ") + "}"
+	}
+
+	return obj.Inspect()
+}
+
+// Error is a runtime error, located at the node that raised it.
+type Error struct {
+	Message  string
+	Location token.TokenLocation
+}
+
+func (e *Error) Type() ObjectType { return ERROR_OBJ }
+func (e *Error) Inspect() string {
+	return fmt.Sprintf("ERROR at %d:%d: %s", e.Location.Line, e.Location.FirstCharIndex, e.Message)
+}
@@ -0,0 +1,259 @@
+package object
+
+import (
+	"gibbon/token"
+	"math"
+	"math/big"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStringHashKey(t *testing.T) {
+	assert := assert.New(t)
+
+	hello1 := &String{Value: "Hello World"}
+	hello2 := &String{Value: "Hello World"}
+	diff := &String{Value: "My name is johnny"}
+
+	assert.Equal(hello1.HashKey(), hello2.HashKey())
+	assert.NotEqual(hello1.HashKey(), diff.HashKey())
+	assert.NotEqual((&Integer{Value: 1}).HashKey(), (&Boolean{Value: true}).HashKey())
+}
+
+func TestBigIntegerHashKey(t *testing.T) {
+	assert := assert.New(t)
+
+	big1 := &BigInteger{Value: new(big.Int).Lsh(big.NewInt(1), 70)}
+	big2 := &BigInteger{Value: new(big.Int).Lsh(big.NewInt(1), 70)}
+	negative := &BigInteger{Value: new(big.Int).Neg(big1.Value)}
+
+	assert.Equal(big1.HashKey(), big2.HashKey())
+	assert.NotEqual(big1.HashKey(), negative.HashKey())
+	assert.Equal("1180591620717411303424", big1.Inspect())
+}
+
+func TestFloatInspect(t *testing.T) {
+	tests := []struct {
+		value    float64
+		expected string
+	}{
+		{1.5, "1.5"},
+		{2, "2.0"},
+		{-0.25, "-0.25"},
+		{1e21, "1e+21"},
+		{math.Inf(1), "+Inf"},
+		{math.NaN(), "NaN"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, (&Float{Value: test.value}).Inspect())
+	}
+}
+
+func TestDecimalInspect(t *testing.T) {
+	tests := []struct {
+		unscaled int64
+		scale    int
+		expected string
+	}{
+		{1250, 2, "12.50"},
+		{-5, 2, "-0.05"},
+		{7, 0, "7"},
+		{0, 3, "0.000"},
+		{-120, 1, "-12.0"},
+	}
+
+	for _, test := range tests {
+		decimal := &Decimal{Unscaled: big.NewInt(test.unscaled), Scale: test.scale}
+		assert.Equal(t, test.expected, decimal.Inspect())
+	}
+}
+
+func TestNewDecimalRounding(t *testing.T) {
+	tests := []struct {
+		mode     RoundingMode
+		expected []string // the rounding of 2.5, -2.5, 1.5, 1.24, -1.26 to one digit less
+	}{
+		{RoundHalfEven, []string{"2", "-2", "2", "1.2", "-1.3"}},
+		{RoundHalfUp, []string{"3", "-3", "2", "1.2", "-1.3"}},
+		{RoundHalfDown, []string{"2", "-2", "1", "1.2", "-1.3"}},
+		{RoundUp, []string{"3", "-3", "2", "1.3", "-1.3"}},
+		{RoundDown, []string{"2", "-2", "1", "1.2", "-1.2"}},
+		{RoundCeiling, []string{"3", "-2", "2", "1.3", "-1.2"}},
+		{RoundFloor, []string{"2", "-3", "1", "1.2", "-1.3"}},
+	}
+
+	values := []struct {
+		value string
+		scale int
+	}{{"2.5", 0}, {"-2.5", 0}, {"1.5", 0}, {"1.24", 1}, {"-1.26", 1}}
+
+	for _, test := range tests {
+		for i, value := range values {
+			rat, _ := new(big.Rat).SetString(value.value)
+			decimal := NewDecimal(rat, value.scale, test.mode)
+			assert.Equalf(t, test.expected[i], decimal.Inspect(), "rounding %s with mode %d", value.value, test.mode)
+		}
+	}
+}
+
+func TestHashKeepsInsertionOrder(t *testing.T) {
+	hash := NewHash()
+	hash.Set(&String{Value: "b"}, &Integer{Value: 1})
+	hash.Set(&Integer{Value: 2}, &Integer{Value: 2})
+	hash.Set(&String{Value: "a"}, &Integer{Value: 3})
+	hash.Set(&String{Value: "b"}, &Integer{Value: 4})
+
+	assert.Equal(t, "{b: 4, 2: 2, a: 3}", hash.Inspect())
+}
+
+func TestInspectCycles(t *testing.T) {
+	assert := assert.New(t)
+
+	array := &Array{Elements: []Object{&Integer{Value: 1}}}
+	array.Elements = append(array.Elements, array)
+	hash := NewHash()
+	hash.Set(&String{Value: "self"}, hash)
+	hash.Set(&String{Value: "array"}, array)
+	shared := &Array{}
+	pair := &Array{Elements: []Object{shared, shared}}
+
+	assert.Equal("[1, [...]]", array.Inspect())
+	assert.Equal("{self: {...}, array: [1, [...]]}", hash.Inspect())
+	assert.Equal("[[], []]", pair.Inspect())
+}
+
+func TestHashKeepsDistinctKeysApart(t *testing.T) {
+	assert := assert.New(t)
+
+	const count = 1 << 16
+	hash := NewHash()
+	for i := 0; i < count; i++ {
+		hash.Set(&String{Value: strconv.Itoa(i)}, &Integer{Value: int64(i)})
+		hash.Set(&BigInteger{Value: new(big.Int).Lsh(big.NewInt(int64(i)), 64)}, &Integer{Value: int64(-i)})
+	}
+
+	assert.Len(hash.Keys, 2*count)
+	value, ok := hash.Get(&String{Value: "12345"})
+	assert.True(ok)
+	assert.Equal(&Integer{Value: 12345}, value)
+	value, ok = hash.Get(&BigInteger{Value: new(big.Int).Lsh(big.NewInt(12345), 64)})
+	assert.True(ok)
+	assert.Equal(&Integer{Value: -12345}, value)
+}
+
+func TestEnvironmentAssign(t *testing.T) {
+	assert := assert.New(t)
+
+	outer := NewEnvironment()
+	outer.Set("x", &Integer{Value: 1})
+	inner := NewEnclosedEnvironment(outer)
+
+	assert.True(inner.Assign("x", &Integer{Value: 2}))
+	assert.False(inner.Assign("y", &Integer{Value: 3}))
+
+	x, _ := outer.Get("x")
+	assert.Equal(&Integer{Value: 2}, x)
+
+	_, ok := inner.Get("y")
+	assert.False(ok)
+}
+
+func TestEnvironmentConstants(t *testing.T) {
+	assert := assert.New(t)
+
+	declaredAt := token.TokenLocation{Line: 3, FirstCharIndex: 7}
+	outer := NewEnvironment()
+	outer.SetConstant("LIMIT", &Integer{Value: 1}, declaredAt)
+	outer.SetConstant("shadowed", &Integer{Value: 1}, declaredAt)
+	inner := NewEnclosedEnvironment(outer)
+	inner.Set("shadowed", &Integer{Value: 2})
+
+	location, constant := inner.Constant("LIMIT")
+	assert.True(constant)
+	assert.Equal(declaredAt, location)
+
+	_, constant = inner.Constant("shadowed")
+	assert.False(constant)
+
+	_, constant = inner.Constant("undeclared")
+	assert.False(constant)
+
+	assert.True(outer.Declares("LIMIT"))
+	assert.False(inner.Declares("LIMIT"))
+
+	outer.Set("LIMIT", &Integer{Value: 2})
+	_, constant = outer.Constant("LIMIT")
+	assert.False(constant)
+}
+
+func TestIterators(t *testing.T) {
+	assert := assert.New(t)
+
+	hash := NewHash()
+	hash.Set(&String{Value: "b"}, &Integer{Value: 2})
+	hash.Set(&String{Value: "a"}, &Integer{Value: 1})
+
+	tests := []struct {
+		iterable       Iterable
+		expectedKeys   []string
+		expectedValues []string
+	}{
+		{&Array{Elements: []Object{&Integer{Value: 7}, &String{Value: "x"}}}, []string{"0", "1"}, []string{"7", "x"}},
+		{&Array{}, []string{}, []string{}},
+		{hash, []string{"b", "a"}, []string{"2", "1"}},
+		{&String{Value: "aé日"}, []string{"0", "1", "2"}, []string{"a", "é", "日"}},
+		{&String{Value: ""}, []string{}, []string{}},
+		{&Range{Start: 3, End: 6}, []string{"0", "1", "2"}, []string{"3", "4", "5"}},
+		{&Range{Start: -1, End: 1, Inclusive: true}, []string{"0", "1", "2"}, []string{"-1", "0", "1"}},
+		{&Range{Start: 2, End: 2}, []string{}, []string{}},
+		{&Range{Start: 2, End: 1, Inclusive: true}, []string{}, []string{}},
+	}
+
+	for _, test := range tests {
+		keys, values := []string{}, []string{}
+
+		iterator := test.iterable.Iterator()
+		for key, value, ok := iterator.Next(); ok; key, value, ok = iterator.Next() {
+			keys = append(keys, key.Inspect())
+			values = append(values, value.Inspect())
+		}
+
+		assert.Equal(test.expectedKeys, keys)
+		assert.Equal(test.expectedValues, values)
+
+		_, _, ok := iterator.Next()
+		assert.False(ok, "exhausted iterators must stay exhausted")
+	}
+}
+
+func TestArrayIteratorSeesAppendedElements(t *testing.T) {
+	array := &Array{Elements: []Object{&Integer{Value: 1}}}
+	iterator := array.Iterator()
+
+	iterator.Next()
+	array.Elements = append(array.Elements, &Integer{Value: 2})
+
+	_, value, ok := iterator.Next()
+	assert.True(t, ok)
+	assert.Equal(t, "2", value.Inspect())
+}
+
+func TestRangeContains(t *testing.T) {
+	assert := assert.New(t)
+
+	exclusive := &Range{Start: 1, End: 3}
+	assert.True(exclusive.Contains(1))
+	assert.True(exclusive.Contains(2))
+	assert.False(exclusive.Contains(3))
+	assert.False(exclusive.Contains(0))
+
+	inclusive := &Range{Start: 1, End: 3, Inclusive: true}
+	assert.True(inclusive.Contains(3))
+	assert.Equal("1..=3", inclusive.Inspect())
+
+	empty := &Range{Start: 3, End: 1}
+	assert.False(empty.Contains(2))
+}
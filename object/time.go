@@ -0,0 +1,22 @@
+package object
+
+import "time"
+
+// Time is an instant, with the location it is shown in.
+type Time struct {
+	Value time.Time
+}
+
+func (t *Time) Type() ObjectType { return TIME_OBJ }
+
+// Inspect writes t in RFC 3339, with fractional seconds only when it has
+// some.
+func (t *Time) Inspect() string { return t.Value.Format(time.RFC3339Nano) }
+
+// Duration is the time elapsed between two instants, to the nanosecond.
+type Duration struct {
+	Value time.Duration
+}
+
+func (d *Duration) Type() ObjectType { return DURATION_OBJ }
+func (d *Duration) Inspect() string  { return d.Value.String() }
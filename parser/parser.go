@@ -1,10 +1,13 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"gibbon/ast"
+	"gibbon/cst"
 	"gibbon/lexer"
 	"gibbon/token"
+	"math/big"
 	"strconv"
 	"strings"
 )
@@ -24,12 +27,39 @@ type Parser struct {
 	errors                 []Error
 	infixExpressionParser  map[token.TokenType]infixParserFn
 	prefixExpressionParser map[token.TokenType]prefixParserFn
+	tokens                 []token.Token // every token read, only recorded by lossless parsers
+	recordTokens           bool
+	scopes                 []map[string]declaration // names declared so far, innermost scope last
+	loopDepth              int                      // number of loops around the current token
+}
+
+// declaration is a name bound by a let or const statement.
+type declaration struct {
+	name     token.Token
+	constant bool
 }
 
 // ============ initialization ============
 
 func NewParser(l *lexer.Lexer) *Parser {
-	p := &Parser{lexer: l, errors: []Error{}}
+	return newParser(l, false)
+}
+
+// NewLosslessParser returns a parser able to build a lossless syntax tree
+// with ParseSyntaxTree. It makes l preserve trivia, so l must not have been
+// read from yet.
+func NewLosslessParser(l *lexer.Lexer) *Parser {
+	l.PreserveTrivia()
+	return newParser(l, true)
+}
+
+func newParser(l *lexer.Lexer, recordTokens bool) *Parser {
+	p := &Parser{
+		lexer:        l,
+		errors:       []Error{},
+		recordTokens: recordTokens,
+		scopes:       []map[string]declaration{{}},
+	}
 	// initializing expression parsers
 	p.initializeInfixParsers()
 	p.initializePrefixParsers()
@@ -51,6 +81,30 @@ func (p *Parser) initializeInfixParsers() {
 	p.registerInfixParser(token.MINUS, p.parseInfixOperator)
 	p.registerInfixParser(token.ASTERISK, p.parseInfixOperator)
 	p.registerInfixParser(token.SLASH, p.parseInfixOperator)
+	p.registerInfixParser(token.AND, p.parseInfixOperator)
+	p.registerInfixParser(token.OR, p.parseInfixOperator)
+	p.registerInfixParser(token.PERCENT, p.parseInfixOperator)
+	p.registerInfixParser(token.POWER, p.parseInfixOperator)
+	p.registerInfixParser(token.BIT_AND, p.parseInfixOperator)
+	p.registerInfixParser(token.BIT_OR, p.parseInfixOperator)
+	p.registerInfixParser(token.BIT_XOR, p.parseInfixOperator)
+	p.registerInfixParser(token.SHIFT_LEFT, p.parseInfixOperator)
+	p.registerInfixParser(token.SHIFT_RIGHT, p.parseInfixOperator)
+	p.registerInfixParser(token.IN, p.parseInfixOperator)
+	p.registerInfixParser(token.RANGE, p.parseRangeExpression)
+	p.registerInfixParser(token.RANGE_INCLUSIVE, p.parseRangeExpression)
+	p.registerInfixParser(token.PIPE, p.parsePipeExpression)
+	p.registerInfixParser(token.LPAREN, p.parseCallExpression)
+	p.registerInfixParser(token.LBRACKET, p.parseIndexExpression)
+	p.registerInfixParser(token.OPTIONAL_LBRACKET, p.parseIndexExpression)
+	p.registerInfixParser(token.OPTIONAL_DOT, p.parseOptionalFieldExpression)
+	p.registerInfixParser(token.NULL_COALESCE, p.parseInfixOperator)
+	p.registerInfixParser(token.ASSIGN, p.parseAssignExpression)
+	p.registerInfixParser(token.PLUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfixParser(token.MINUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfixParser(token.ASTERISK_ASSIGN, p.parseAssignExpression)
+	p.registerInfixParser(token.SLASH_ASSIGN, p.parseAssignExpression)
+	p.registerInfixParser(token.PERCENT_ASSIGN, p.parseAssignExpression)
 }
 
 func (p *Parser) initializePrefixParsers() {
@@ -58,10 +112,23 @@ func (p *Parser) initializePrefixParsers() {
 	p.registerPrefixParser(token.IDENT, p.parseIdentifier)
 	p.registerPrefixParser(token.TRUE, p.parseBoolean)
 	p.registerPrefixParser(token.FALSE, p.parseBoolean)
+	p.registerPrefixParser(token.NULL, p.parseNullLiteral)
 	p.registerPrefixParser(token.INT, p.parseIntegerLiteral)
+	p.registerPrefixParser(token.FLOAT, p.parseFloatLiteral)
+	p.registerPrefixParser(token.DECIMAL, p.parseDecimalLiteral)
+	p.registerPrefixParser(token.STRING, p.parseStringLiteral)
+	p.registerPrefixParser(token.STRING_HEAD, p.parseInterpolatedString)
+	p.registerPrefixParser(token.LBRACKET, p.parseArrayLiteral)
+	p.registerPrefixParser(token.LBRACE, p.parseHashLiteral)
+	p.registerPrefixParser(token.LPAREN, p.parseGroupedExpression)
+	p.registerPrefixParser(token.FUNCTION, p.parseFunctionLiteral)
+	p.registerPrefixParser(token.IF, p.parseIfExpression)
+	p.registerPrefixParser(token.WHILE, p.parseWhileExpression)
+	p.registerPrefixParser(token.FOR, p.parseForInExpression)
 	p.registerPrefixParser(token.BANG, p.parsePrefixOperator)
 	p.registerPrefixParser(token.MINUS, p.parsePrefixOperator)
 	p.registerPrefixParser(token.PLUS, p.parsePrefixOperator)
+	p.registerPrefixParser(token.TILDE, p.parsePrefixOperator)
 }
 
 // ============ mutation ============
@@ -77,6 +144,10 @@ func (p *Parser) registerInfixParser(t token.TokenType, parser infixParserFn) {
 func (p *Parser) nextToken() {
 	p.currentToken = p.peekToken
 	p.peekToken = p.lexer.NextToken()
+
+	if p.recordTokens {
+		p.tokens = append(p.tokens, p.peekToken)
+	}
 }
 
 // ============ parsing ============
@@ -97,29 +168,95 @@ func (p *Parser) ParseProgram() *ast.Program {
 	return program
 }
 
+// ParseSyntaxTree parses the program like ParseProgram, also keeping every
+// token each statement was parsed from. Only trees built by lossless parsers
+// carry the trivia needed to reproduce their source.
+func (p *Parser) ParseSyntaxTree() *cst.Tree {
+	tree := &cst.Tree{Statements: []*cst.Statement{}}
+
+	if !p.recordTokens {
+		p.tokens = []token.Token{p.currentToken, p.peekToken}
+		p.recordTokens = true
+	}
+
+	for !p.curTokenIs(token.EOF) {
+		start := p.currentTokenIndex()
+		stmt := p.parseStatement()
+		tokens := p.tokens[start : p.currentTokenIndex()+1]
+
+		tree.Statements = append(tree.Statements, cst.NewStatement(stmt, tokens))
+
+		p.nextToken()
+	}
+
+	tree.EOF = p.currentToken
+	return tree
+}
+
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.currentToken.Type {
-	case token.LET:
-		return p.parseLetStatement()
+	case token.LET, token.CONST:
+		if stmt := p.parseLetStatement(); stmt != nil {
+			return stmt
+		}
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.BREAK, token.CONTINUE:
+		return p.parseLoopControlStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
+
+	return nil
 }
 
 func (p *Parser) parseIdentifier() ast.Expression {
 	return &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
 }
 
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	value, err := strconv.ParseFloat(p.currentToken.Literal, 64)
+	if err != nil {
+		msg := fmt.Sprintf("Could not parse '%q' as float literal", p.currentToken.Literal)
+		p.errors = append(p.errors, Error{message: msg, location: p.currentToken.Location})
+		return nil
+	}
+
+	return &ast.FloatLiteral{Token: p.currentToken, Value: value}
+}
+
+func (p *Parser) parseDecimalLiteral() ast.Expression {
+	digits := strings.TrimSuffix(p.currentToken.Literal, "d")
+	integerPart, fraction, _ := strings.Cut(digits, ".")
+
+	unscaled, ok := new(big.Int).SetString(integerPart+fraction, 10)
+	if !ok {
+		msg := fmt.Sprintf("Could not parse '%q' as decimal literal", p.currentToken.Literal)
+		p.errors = append(p.errors, Error{message: msg, location: p.currentToken.Location})
+		return nil
+	}
+
+	return &ast.DecimalLiteral{Token: p.currentToken, Unscaled: unscaled, Scale: len(fraction)}
+}
+
 func (p *Parser) parseBoolean() ast.Expression {
-	value := strings.ToUpper(p.currentToken.Literal) == token.TRUE
-	return &ast.Boolean{Token: p.currentToken, Value: value}
+	return &ast.Boolean{Token: p.currentToken, Value: p.curTokenIs(token.TRUE)}
+}
+
+func (p *Parser) parseNullLiteral() ast.Expression {
+	return &ast.NullLiteral{Token: p.currentToken}
 }
 
 func (p *Parser) parseIntegerLiteral() ast.Expression {
 	value, err := strconv.ParseInt(p.currentToken.Literal, 10, 64)
 
+	if errors.Is(err, strconv.ErrRange) {
+		// literals beyond int64 are big integers
+		if bigValue, ok := new(big.Int).SetString(p.currentToken.Literal, 10); ok {
+			return &ast.IntegerLiteral{Token: p.currentToken, Big: bigValue}
+		}
+	}
+
 	if err != nil {
 		msg := fmt.Sprintf("Could not parse '%q' as integer literal", p.currentToken.Literal)
 		p.errors = append(p.errors, Error{message: msg, location: p.currentToken.Location})
@@ -129,8 +266,166 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	return &ast.IntegerLiteral{Token: p.currentToken, Value: value}
 }
 
+func (p *Parser) parseStringLiteral() ast.Expression {
+	value, err := unquoteString(p.currentToken)
+
+	if err != nil {
+		p.errors = append(p.errors, Error{message: err.Error(), location: p.currentToken.Location})
+		return nil
+	}
+
+	return &ast.StringLiteral{Token: p.currentToken, Value: value}
+}
+
+// parseInterpolatedString parses the parts of a string from its head to its
+// tail, along with the expressions embedded between them.
+func (p *Parser) parseInterpolatedString() ast.Expression {
+	str := &ast.InterpolatedString{Token: p.currentToken}
+
+	for {
+		part := p.parseStringLiteral()
+		if part == nil {
+			return nil
+		}
+
+		str.Parts = append(str.Parts, part)
+		if p.curTokenIs(token.STRING_TAIL) {
+			return str
+		}
+
+		if p.peekTokenIs(token.STRING_MIDDLE) || p.peekTokenIs(token.STRING_TAIL) {
+			p.emptyInterpolationError()
+			return nil
+		}
+
+		p.nextToken()
+		str.Parts = append(str.Parts, p.parseExpression(LOWEST))
+
+		if p.peekTokenIs(token.STRING_MIDDLE) {
+			p.nextToken()
+		} else if !p.expectPeek(token.STRING_TAIL) {
+			return nil
+		}
+	}
+}
+
+func (p *Parser) parseGroupedExpression() ast.Expression {
+	p.nextToken()
+	expression := p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if call, ok := expression.(*ast.CallExpression); ok {
+		call.Grouped = true
+	}
+
+	return expression
+}
+
+func (p *Parser) parseFunctionLiteral() ast.Expression {
+	function := &ast.FunctionLiteral{Token: p.currentToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	// the parameters are scoped to the body
+	p.openScope()
+	defer p.closeScope()
+
+	if function.Parameters = p.parseFunctionParameters(); function.Parameters == nil {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	// break and continue cannot reach the loops around a function
+	loopDepth := p.loopDepth
+	p.loopDepth = 0
+	function.Body = p.parseBlockStatement()
+	p.loopDepth = loopDepth
+
+	return function
+}
+
+// parseFunctionParameters parses comma separated identifiers up to a ')',
+// declaring them in the current scope. It returns nil when the list is
+// malformed.
+func (p *Parser) parseFunctionParameters() []*ast.Identifier {
+	parameters := []*ast.Identifier{}
+
+	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		return parameters
+	}
+
+	for {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		parameter := &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+		parameters = append(parameters, parameter)
+		p.declare(parameter.Token, false)
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	return parameters
+}
+
+func (p *Parser) parseArrayLiteral() ast.Expression {
+	array := &ast.ArrayLiteral{Token: p.currentToken}
+
+	array.Elements = p.parseExpressionList(token.RBRACKET)
+	if array.Elements == nil {
+		return nil
+	}
+
+	return array
+}
+
+func (p *Parser) parseHashLiteral() ast.Expression {
+	hash := &ast.HashLiteral{Token: p.currentToken, Pairs: []ast.HashPair{}}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		key := p.parseExpression(LOWEST)
+
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+
+		p.nextToken()
+		value := p.parseExpression(LOWEST)
+
+		hash.Pairs = append(hash.Pairs, ast.HashPair{Key: key, Value: value})
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+
+	return hash
+}
+
 func (p *Parser) parseLetStatement() *ast.LetStatement {
-	stmt := &ast.LetStatement{Token: p.currentToken}
+	stmt := &ast.LetStatement{Token: p.currentToken, Constant: p.curTokenIs(token.CONST)}
 
 	if !p.expectPeek(token.IDENT) {
 		return nil
@@ -138,6 +433,10 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 
 	stmt.Name = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
 
+	if previous, ok := p.scopes[len(p.scopes)-1][stmt.Name.Value]; ok && previous.constant {
+		p.constantRedeclarationError(stmt.Name.Token, previous)
+	}
+
 	if !p.expectPeek(token.ASSIGN) {
 		return nil
 	}
@@ -151,19 +450,45 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 		return nil
 	}
 
+	p.declare(stmt.Name.Token, stmt.Constant)
 	return stmt
 }
 
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	stmt := &ast.ReturnStatement{Token: p.currentToken}
 
-	for !p.curTokenIs(token.SEMICOLON) {
+	if !p.peekTokenIs(token.SEMICOLON) && !p.peekTokenIs(token.RBRACE) && !p.peekTokenIs(token.EOF) {
+		p.nextToken()
+		stmt.ReturnValue = p.parseExpression(LOWEST)
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
 
 	return stmt
 }
 
+// parseLoopControlStatement parses a break or continue statement, which may
+// only appear in the body of a loop.
+func (p *Parser) parseLoopControlStatement() ast.Statement {
+	keyword := p.currentToken
+
+	if p.loopDepth == 0 {
+		p.outsideLoopError(keyword)
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	if keyword.Type == token.BREAK {
+		return &ast.BreakStatement{Token: keyword}
+	}
+
+	return &ast.ContinueStatement{Token: keyword}
+}
+
 func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	stmt := &ast.ExpressionStatement{
 		Token:      p.currentToken,
@@ -212,7 +537,7 @@ func (p *Parser) parsePrefixOperator() ast.Expression {
 func (p *Parser) parseInfixOperator(left ast.Expression) ast.Expression {
 	operatorToken := p.currentToken
 	p.nextToken()
-	right := p.parseExpression(p.getTokenPrecedence(operatorToken))
+	right := p.parseExpression(p.getRightOperandPrecedence(operatorToken))
 
 	return &ast.InfixExpression{
 		Token:    operatorToken,
@@ -222,30 +547,293 @@ func (p *Parser) parseInfixOperator(left ast.Expression) ast.Expression {
 	}
 }
 
+func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
+	call := &ast.CallExpression{Token: p.currentToken, Function: function}
+
+	if call.Arguments = p.parseExpressionList(token.RPAREN); call.Arguments == nil {
+		return nil
+	}
+
+	return call
+}
+
+func (p *Parser) parsePipeExpression(left ast.Expression) ast.Expression {
+	expression := &ast.PipeExpression{Token: p.currentToken, Left: left}
+
+	p.nextToken()
+	expression.Right = p.parseExpression(p.getRightOperandPrecedence(expression.Token))
+
+	return expression
+}
+
+func (p *Parser) parseRangeExpression(start ast.Expression) ast.Expression {
+	expression := &ast.RangeExpression{
+		Token:     p.currentToken,
+		Start:     start,
+		Inclusive: p.curTokenIs(token.RANGE_INCLUSIVE),
+	}
+
+	p.nextToken()
+	expression.End = p.parseExpression(p.getRightOperandPrecedence(expression.Token))
+
+	return expression
+}
+
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	expression := &ast.IndexExpression{
+		Token:    p.currentToken,
+		Left:     left,
+		Optional: p.curTokenIs(token.OPTIONAL_LBRACKET),
+	}
+
+	p.nextToken()
+	expression.Index = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+
+	return expression
+}
+
+// parseOptionalFieldExpression parses "left?.name", which reads the "name" key
+// of the hash left unless left is null.
+func (p *Parser) parseOptionalFieldExpression(left ast.Expression) ast.Expression {
+	expression := &ast.IndexExpression{Token: p.currentToken, Left: left, Optional: true}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	expression.Index = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+
+	return expression
+}
+
+func (p *Parser) parseAssignExpression(target ast.Expression) ast.Expression {
+	expression := &ast.AssignExpression{
+		Token:    p.currentToken,
+		Target:   target,
+		Operator: p.currentToken.Literal,
+	}
+
+	switch target := target.(type) {
+	case *ast.Identifier:
+		if previous, ok := p.lookupDeclaration(target.Value); ok && previous.constant {
+			p.constantAssignmentError(target.Token, previous)
+		}
+	case *ast.IndexExpression:
+		if target.Optional {
+			p.invalidAssignmentTargetError(target)
+			return nil
+		}
+	default:
+		p.invalidAssignmentTargetError(target)
+		return nil
+	}
+
+	p.nextToken()
+	expression.Value = p.parseExpression(p.getRightOperandPrecedence(expression.Token))
+
+	return expression
+}
+
+func (p *Parser) parseIfExpression() ast.Expression {
+	expression := &ast.IfExpression{Token: p.currentToken}
+
+	if expression.Condition = p.parseCondition(); expression.Condition == nil {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	expression.Consequence = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.ELSE) {
+		p.nextToken()
+
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+
+		expression.Alternative = p.parseBlockStatement()
+	}
+
+	return expression
+}
+
+func (p *Parser) parseWhileExpression() ast.Expression {
+	expression := &ast.WhileExpression{Token: p.currentToken}
+
+	if expression.Condition = p.parseCondition(); expression.Condition == nil {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	p.loopDepth++
+	expression.Body = p.parseBlockStatement()
+	p.loopDepth--
+
+	return expression
+}
+
+func (p *Parser) parseForInExpression() ast.Expression {
+	expression := &ast.ForInExpression{Token: p.currentToken}
+
+	if !p.expectPeek(token.LPAREN) || !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	expression.Value = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+
+	if p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		expression.Key = expression.Value
+		expression.Value = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+	}
+
+	if !p.expectPeek(token.IN) {
+		return nil
+	}
+
+	p.nextToken()
+	expression.Iterable = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) || !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	// the loop variables are scoped to the body
+	p.openScope()
+	defer p.closeScope()
+
+	if expression.Key != nil {
+		p.declare(expression.Key.Token, false)
+	}
+	p.declare(expression.Value.Token, false)
+
+	p.loopDepth++
+	expression.Body = p.parseBlockStatement()
+	p.loopDepth--
+
+	return expression
+}
+
+// parseCondition parses the parenthesized condition following an if or a
+// while keyword.
+func (p *Parser) parseCondition() ast.Expression {
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	p.nextToken()
+	condition := p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	return condition
+}
+
+// parseBlockStatement parses the statements up to the '}' closing the
+// current token. Names declared in the block are scoped to it.
+func (p *Parser) parseBlockStatement() *ast.BlockStatement {
+	block := &ast.BlockStatement{Token: p.currentToken, Statements: []ast.Statement{}}
+
+	p.openScope()
+	defer p.closeScope()
+
+	p.nextToken()
+
+	for !p.curTokenIs(token.RBRACE) {
+		if p.curTokenIs(token.EOF) {
+			p.unterminatedBlockError(block.Token)
+			return block
+		}
+
+		if stmt := p.parseStatement(); stmt != nil {
+			block.Statements = append(block.Statements, stmt)
+		}
+
+		p.nextToken()
+	}
+
+	return block
+}
+
+// parseExpressionList parses comma separated expressions up to the end token,
+// returning nil when the list is malformed.
+func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
+	list := []ast.Expression{}
+
+	if p.peekTokenIs(end) {
+		p.nextToken()
+		return list
+	}
+
+	p.nextToken()
+	list = append(list, p.parseExpression(LOWEST))
+
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		p.nextToken()
+		list = append(list, p.parseExpression(LOWEST))
+	}
+
+	if !p.expectPeek(end) {
+		return nil
+	}
+
+	return list
+}
+
 // ============ helpers ============
 
 const (
-	_ int = iota
-	LOWEST
-	EQUALS
-	LESSGREATER
-	SUM
-	PRODUCT
-	PREFIX
-	CALL // method calls
+	LOWEST      = token.LowestPrecedence
+	ASSIGN      = token.AssignPrecedence
+	COALESCE    = token.NullCoalescePrecedence
+	LOGICAL_OR  = token.LogicalOrPrecedence
+	LOGICAL_AND = token.LogicalAndPrecedence
+	EQUALS      = token.EqualsPrecedence
+	LESSGREATER = token.LessGreaterPrecedence
+	RANGE       = token.RangePrecedence
+	BITWISE_OR  = token.BitwiseOrPrecedence
+	BITWISE_XOR = token.BitwiseXorPrecedence
+	BITWISE_AND = token.BitwiseAndPrecedence
+	SHIFT       = token.ShiftPrecedence
+	SUM         = token.SumPrecedence
+	PRODUCT     = token.ProductPrecedence
+	PREFIX      = token.PrefixPrecedence
+	EXPONENT    = token.ExponentPrecedence
+	CALL        = token.CallPrecedence // method calls
+	INDEX       = token.IndexPrecedence
 )
 
-var precedences = map[token.TokenType]int{
-	token.EQUAL:     EQUALS,
-	token.DIFFERENT: EQUALS,
-	token.LTE:       LESSGREATER,
-	token.GTE:       LESSGREATER,
-	token.LT:        LESSGREATER,
-	token.GT:        LESSGREATER,
-	token.PLUS:      SUM,
-	token.MINUS:     SUM,
-	token.ASTERISK:  PRODUCT,
-	token.SLASH:     PRODUCT,
+// precedences holds every binary operator, as declared in the token package
+var precedences = binaryOperatorPrecedences()
+
+func binaryOperatorPrecedences() map[token.TokenType]int {
+	precedences := make(map[token.TokenType]int)
+
+	for _, t := range token.TokenTypes() {
+		if precedence := t.Precedence(); precedence > 0 {
+			precedences[t] = precedence
+		}
+	}
+
+	return precedences
 }
 
 func (p *Parser) getTokenPrecedence(t token.Token) int {
@@ -256,6 +844,82 @@ func (p *Parser) getTokenPrecedence(t token.Token) int {
 	return LOWEST
 }
 
+// currentTokenIndex is the index of the current token in the recorded tokens,
+// which always end with the peek token.
+func (p *Parser) currentTokenIndex() int {
+	return len(p.tokens) - 2
+}
+
+func (p *Parser) openScope() {
+	p.scopes = append(p.scopes, map[string]declaration{})
+}
+
+func (p *Parser) closeScope() {
+	p.scopes = p.scopes[:len(p.scopes)-1]
+}
+
+func (p *Parser) declare(name token.Token, constant bool) {
+	p.scopes[len(p.scopes)-1][name.Literal] = declaration{name: name, constant: constant}
+}
+
+// lookupDeclaration finds the innermost declaration of name.
+func (p *Parser) lookupDeclaration(name string) (declaration, bool) {
+	for i := len(p.scopes) - 1; i >= 0; i-- {
+		if declaration, ok := p.scopes[i][name]; ok {
+			return declaration, true
+		}
+	}
+
+	return declaration{}, false
+}
+
+// getRightOperandPrecedence is the precedence to parse the right operand of
+// operator with. Parsing it one level lower for right-associative operators
+// lets it take the next operators of the same precedence, grouping them from
+// the right.
+func (p *Parser) getRightOperandPrecedence(operator token.Token) int {
+	precedence := p.getTokenPrecedence(operator)
+
+	if operator.Type.RightAssociative() {
+		precedence--
+	}
+
+	return precedence
+}
+
+// unquoteString strips the delimiters off a string literal, or a part of an
+// interpolated string, and replaces its escape sequences.
+func unquoteString(t token.Token) (string, error) {
+	var out strings.Builder
+	content := t.Literal[1 : len(t.Literal)-1]
+	if t.Type == token.STRING_HEAD || t.Type == token.STRING_MIDDLE {
+		content = t.Literal[1 : len(t.Literal)-2]
+	}
+
+	for i := 0; i < len(content); i++ {
+		if content[i] != '\\' {
+			out.WriteByte(content[i])
+			continue
+		}
+
+		i++
+		switch content[i] {
+		case 'n':
+			out.WriteByte('\n')
+		case 't':
+			out.WriteByte('\t')
+		case 'r':
+			out.WriteByte('\r')
+		case '"', '\\', '$':
+			out.WriteByte(content[i])
+		default:
+			return "", fmt.Errorf("unknown escape sequence \\%c in string literal", content[i])
+		}
+	}
+
+	return out.String(), nil
+}
+
 func (p *Parser) curTokenIs(t token.TokenType) bool {
 	return p.currentToken.Type == t
 }
@@ -280,6 +944,14 @@ func (p *Parser) Errors() []Error {
 	return p.errors
 }
 
+func (e Error) Message() string {
+	return e.message
+}
+
+func (e Error) Location() token.TokenLocation {
+	return e.location
+}
+
 func (p *Parser) peekError(expected token.TokenType) {
 	msg := fmt.Sprintf("expected next token to be %s, got %s instead", expected, p.peekToken.Type)
 	p.errors = append(p.errors, Error{message: msg, location: p.peekToken.Location})
@@ -290,6 +962,39 @@ func (p *Parser) noPrefixParserFnError(t token.TokenType) {
 	p.errors = append(p.errors, Error{message: msg, location: p.currentToken.Location})
 }
 
+func (p *Parser) invalidAssignmentTargetError(target ast.Expression) {
+	msg := fmt.Sprintf("cannot assign to %s, only identifiers and non-optional index expressions can be assigned", target)
+	p.errors = append(p.errors, Error{message: msg, location: p.currentToken.Location})
+}
+
+func (p *Parser) constantAssignmentError(at token.Token, constant declaration) {
+	location := constant.name.Location
+	msg := fmt.Sprintf("cannot assign to constant %s, declared at %d:%d", at.Literal, location.Line, location.FirstCharIndex)
+	p.errors = append(p.errors, Error{message: msg, location: at.Location})
+}
+
+func (p *Parser) constantRedeclarationError(at token.Token, constant declaration) {
+	location := constant.name.Location
+	msg := fmt.Sprintf("cannot redeclare constant %s, declared at %d:%d", at.Literal, location.Line, location.FirstCharIndex)
+	p.errors = append(p.errors, Error{message: msg, location: at.Location})
+}
+
+func (p *Parser) emptyInterpolationError() {
+	msg := "expected an expression between ${ and }"
+	p.errors = append(p.errors, Error{message: msg, location: p.peekToken.Location})
+}
+
+func (p *Parser) outsideLoopError(keyword token.Token) {
+	msg := fmt.Sprintf("%s outside of a loop", keyword.Literal)
+	p.errors = append(p.errors, Error{message: msg, location: keyword.Location})
+}
+
+func (p *Parser) unterminatedBlockError(open token.Token) {
+	location := open.Location
+	msg := fmt.Sprintf("expected } to close the block opened at %d:%d, got EOF instead", location.Line, location.FirstCharIndex)
+	p.errors = append(p.errors, Error{message: msg, location: p.currentToken.Location})
+}
+
 func (p *Parser) noInfixParserFnError(t token.TokenType) {
 	msg := fmt.Sprintf("token type %q has no registered INFIX parser functions", t)
 	p.errors = append(p.errors, Error{message: msg, location: p.currentToken.Location})
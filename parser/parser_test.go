@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"gibbon/ast"
 	"gibbon/lexer"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -78,6 +79,62 @@ let 1;
 	testError(t, errors[2], "expected next token to be IDENT", 4, 5)
 }
 
+func TestConstStatementParse(t *testing.T) {
+	assert := assert.New(t)
+
+	input := []byte(`const LIMIT = 10;
+let count = 0;
+count = LIMIT;
+const ITEMS = [1];
+ITEMS[0] = 2;
+`)
+
+	l := lexer.NewLexer(bytes.NewReader(input), "input")
+	parser := NewParser(l)
+	program := parser.ParseProgram()
+	ensureNoErrors(t, parser)
+
+	if !assert.Len(program.Statements, 5) {
+		t.FailNow()
+	}
+
+	constStmt, ok := program.Statements[0].(*ast.LetStatement)
+	if !assert.Truef(ok, "statement not of type *ast.LetStatement, got=%T", program.Statements[0]) {
+		t.FailNow()
+	}
+
+	assert.True(constStmt.Constant)
+	assert.Equal("const", constStmt.TokenLiteral())
+	assert.Equal("LIMIT", constStmt.Name.Value)
+	assert.Equal("const LIMIT = 10;", constStmt.String())
+
+	letStmt := program.Statements[1].(*ast.LetStatement)
+	assert.False(letStmt.Constant)
+}
+
+func TestConstStatementErrors(t *testing.T) {
+	input := []byte(`const LIMIT = 10;
+LIMIT = 11;
+  LIMIT += 1;
+const LIMIT = 12;
+let LIMIT = 13;
+`)
+
+	l := lexer.NewLexer(bytes.NewReader(input), "input")
+	parser := NewParser(l)
+	parser.ParseProgram()
+	errors := parser.Errors()
+
+	if !assert.Len(t, errors, 4) {
+		t.FailNow()
+	}
+
+	testError(t, errors[0], "cannot assign to constant LIMIT, declared at 1:7", 2, 1)
+	testError(t, errors[1], "cannot assign to constant LIMIT, declared at 1:7", 3, 3)
+	testError(t, errors[2], "cannot redeclare constant LIMIT, declared at 1:7", 4, 7)
+	testError(t, errors[3], "cannot redeclare constant LIMIT, declared at 4:7", 5, 5)
+}
+
 func TestIdentifierExpression(t *testing.T) {
 	assert := assert.New(t)
 
@@ -173,6 +230,74 @@ func TestIntegerLiteral(t *testing.T) {
 	testIntegerLiteral(t, integerExpression.Expression, 3)
 }
 
+func TestBigIntegerLiteral(t *testing.T) {
+	assert := assert.New(t)
+
+	input := []byte(`123456789012345678901234567890;`)
+	l := lexer.NewLexer(bytes.NewReader(input), "input")
+	parser := NewParser(l)
+	program := parser.ParseProgram()
+	ensureNoErrors(t, parser)
+
+	assert.Len(program.Statements, 1)
+
+	statement, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !assert.True(ok, "statement not of type *ast.ExpressionStatement") {
+		t.FailNow()
+	}
+
+	integer, ok := statement.Expression.(*ast.IntegerLiteral)
+	if !assert.True(ok, "expression not of type *ast.IntegerLiteral") || !assert.NotNil(integer.Big) {
+		t.FailNow()
+	}
+
+	assert.Equal("123456789012345678901234567890", integer.Big.String())
+	assert.Equal("123456789012345678901234567890", integer.String())
+}
+
+func TestFloatLiteral(t *testing.T) {
+	l := lexer.NewLexerFromBytes([]byte(`3.25;`), "input")
+	parser := NewParser(l)
+	program := parser.ParseProgram()
+	ensureNoErrors(t, parser)
+
+	statement := program.Statements[0].(*ast.ExpressionStatement)
+	float, ok := statement.Expression.(*ast.FloatLiteral)
+	if assert.Truef(t, ok, "expression not of type *ast.FloatLiteral, got=%T", statement.Expression) {
+		assert.Equal(t, 3.25, float.Value)
+		assert.Equal(t, "3.25", float.String())
+	}
+}
+
+func TestDecimalLiteral(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedUnscaled string
+		expectedScale    int
+	}{
+		{"12.50d", "1250", 2},
+		{"7d", "7", 0},
+		{"0.001d", "1", 3},
+	}
+
+	for _, test := range tests {
+		l := lexer.NewLexerFromBytes([]byte(test.input), "input")
+		parser := NewParser(l)
+		program := parser.ParseProgram()
+		ensureNoErrors(t, parser)
+
+		statement := program.Statements[0].(*ast.ExpressionStatement)
+		decimal, ok := statement.Expression.(*ast.DecimalLiteral)
+		if !assert.Truef(t, ok, "expression not of type *ast.DecimalLiteral, got=%T", statement.Expression) {
+			continue
+		}
+
+		assert.Equal(t, test.expectedUnscaled, decimal.Unscaled.String())
+		assert.Equal(t, test.expectedScale, decimal.Scale)
+		assert.Equal(t, test.input, decimal.String())
+	}
+}
+
 func TestPrefixExpression(t *testing.T) {
 	tests := []struct {
 		input                []byte
@@ -183,6 +308,7 @@ func TestPrefixExpression(t *testing.T) {
 		{[]byte(`!2`), "!", 2},
 		{[]byte(`-7;`), "-", 7},
 		{[]byte(`+99182346;`), "+", 99182346},
+		{[]byte(`~12`), "~", 12},
 	}
 
 	for _, test := range tests {
@@ -230,6 +356,15 @@ func TestInfixExpressionWithIntegerLiterals(t *testing.T) {
 		{[]byte(`0 >= 20`), ">=", 0, 20},
 		{[]byte(`4 <= 10`), "<=", 4, 10},
 		{[]byte(`5 != 29`), "!=", 5, 29},
+		{[]byte(`1 && 0`), "&&", 1, 0},
+		{[]byte(`0 || 1`), "||", 0, 1},
+		{[]byte(`7 % 2`), "%", 7, 2},
+		{[]byte(`2 ** 8`), "**", 2, 8},
+		{[]byte(`6 & 3`), "&", 6, 3},
+		{[]byte(`6 | 3`), "|", 6, 3},
+		{[]byte(`6 ^ 3`), "^", 6, 3},
+		{[]byte(`1 << 4`), "<<", 1, 4},
+		{[]byte(`16 >> 2`), ">>", 16, 2},
 	}
 
 	for _, test := range tests {
@@ -319,6 +454,35 @@ func TestExpressionOperatorPrecedence(t *testing.T) {
 		{[]byte(`!a >= !b`), "((!a) >= (!b))"},
 		{[]byte(`!a > !b < c`), "(((!a) > (!b)) < c)"},
 		{[]byte(`apples * price + - discount * buyers`), "((apples * price) + ((-discount) * buyers))"},
+		{[]byte(`a && b || c && d`), "((a && b) || (c && d))"},
+		{[]byte(`a || b || c`), "((a || b) || c)"},
+		{[]byte(`a == b && c < d || !e`), "(((a == b) && (c < d)) || (!e))"},
+		{[]byte(`2 ** 3 ** 2`), "(2 ** (3 ** 2))"},
+		{[]byte(`a * b ** c`), "(a * (b ** c))"},
+		{[]byte(`-a ** b + c`), "((-(a ** b)) + c)"},
+		{[]byte(`a ** -b`), "(a ** (-b))"},
+		{[]byte(`a % b * c`), "((a % b) * c)"},
+		{[]byte(`a | b ^ c & d`), "(a | (b ^ (c & d)))"},
+		{[]byte(`a & b == c`), "((a & b) == c)"},
+		{[]byte(`a << b + c`), "(a << (b + c))"},
+		{[]byte(`a >> b & ~c`), "((a >> b) & (~c))"},
+		{[]byte(`a * b[0]`), "(a * (b[0]))"},
+		{[]byte(`a[0][1] + -b[2]`), "(((a[0])[1]) + (-(b[2])))"},
+		{[]byte(`[1, 2 * 3][0] * 4`), "(([1, (2 * 3)][0]) * 4)"},
+		{[]byte(`x in 0..n + 1`), "(x in (0..(n + 1)))"},
+		{[]byte(`x in 1..=a | b`), "(x in (1..=(a | b)))"},
+		{[]byte(`1..3 == r`), "((1..3) == r)"},
+		{[]byte(`a in xs && b in ys`), "((a in xs) && (b in ys))"},
+		{[]byte(`xs[1..3] + ys`), "((xs[(1..3)]) + ys)"},
+		{[]byte(`a ?? b || c`), "(a ?? (b || c))"},
+		{[]byte(`a ?? b ?? c`), "((a ?? b) ?? c)"},
+		{[]byte(`a?.b ?? c?[0] + 1`), "((a?.b) ?? ((c?[0]) + 1))"},
+		{[]byte(`x == null`), "(x == null)"},
+		{[]byte(`(a + b) * c`), "((a + b) * c)"},
+		{[]byte(`-(a + b) * c`), "((-(a + b)) * c)"},
+		{[]byte(`!(true == true) == false`), "((!(true == true)) == false)"},
+		{[]byte(`a + add(b * c) + d`), "((a + add((b * c))) + d)"},
+		{[]byte(`add(a, b)[0] * f(g(1))`), "((add(a, b)[0]) * f(g(1)))"},
 	}
 
 	for _, test := range tests {
@@ -349,36 +513,672 @@ func TestExpressionOperatorPrecedence(t *testing.T) {
 	}
 }
 
-func TestReturnStatements(t *testing.T) {
+func TestStringLiteral(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedValue string
+	}{
+		{`"hello world";`, "hello world"},
+		{`""`, ""},
+		{`"say \"hi\"\n\tand \\ leave"`, "say \"hi\"\n\tand \\ leave"},
+		{`"ünïcödé"`, "ünïcödé"},
+		{`"costs \${price}"`, "costs ${price}"},
+	}
+
+	for _, test := range tests {
+		assert := assert.New(t)
+
+		program := parseSingleStatementProgram(t, test.input)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+		literal, ok := stmt.Expression.(*ast.StringLiteral)
+		if !assert.Truef(ok, "expression not of type *ast.StringLiteral, got=%T", stmt.Expression) {
+			t.FailNow()
+		}
+
+		assert.Equal(test.expectedValue, literal.Value)
+		assert.Equal(strings.TrimSuffix(test.input, ";"), literal.String())
+	}
+}
+
+func TestStringLiteralErrors(t *testing.T) {
+	l := lexer.NewLexerFromBytes([]byte(`let a = 1;
+let b = "bad \q escape";`), "input")
+	parser := NewParser(l)
+	parser.ParseProgram()
+
+	if !assert.Len(t, parser.Errors(), 1) {
+		t.FailNow()
+	}
+	testError(t, parser.Errors()[0], "unknown escape sequence \\q", 2, 9)
+}
+
+func TestInterpolatedString(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedParts  []string
+		expectedString string
+	}{
+		{
+			`"hello ${name}, you have ${count + 1} items"`,
+			[]string{"hello ", "name", ", you have ", "(count + 1)", " items"},
+			`"hello ${name}, you have ${(count + 1)} items"`,
+		},
+		{`"${a}${b}"`, []string{"", "a", "", "b", ""}, `"${a}${b}"`},
+		{`"tab\t${ {"k": 1}["k"] }\""`, []string{"tab\t", `({"k": 1}["k"])`, "\""}, `"tab\t${({"k": 1}["k"])}\""`},
+		{`"outer ${"inner ${x}"}"`, []string{"outer ", `"inner ${x}"`, ""}, `"outer ${"inner ${x}"}"`},
+	}
+
+	for _, test := range tests {
+		assert := assert.New(t)
+
+		program := parseSingleStatementProgram(t, test.input)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+		str, ok := stmt.Expression.(*ast.InterpolatedString)
+		if !assert.Truef(ok, "expression not of type *ast.InterpolatedString, got=%T", stmt.Expression) {
+			t.FailNow()
+		}
+
+		parts := []string{}
+		for i, part := range str.Parts {
+			if literal, ok := part.(*ast.StringLiteral); ok && i%2 == 0 {
+				parts = append(parts, literal.Value)
+			} else {
+				parts = append(parts, part.String())
+			}
+		}
+
+		assert.Equal(test.expectedParts, parts)
+		assert.Equal(test.expectedString, str.String())
+	}
+}
+
+func TestInterpolatedStringErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+		expectedColumn  int
+	}{
+		{`"a ${} b"`, "expected an expression between ${ and }", 6},
+		{`"a ${x y} b"`, "expected next token to be STRING_TAIL, got IDENT instead", 8},
+		{`"a ${x`, "expected next token to be STRING_TAIL, got EOF instead", 7},
+		{`"a ${x} \q"`, "unknown escape sequence \\q", 7},
+	}
+
+	for _, test := range tests {
+		l := lexer.NewLexerFromBytes([]byte(test.input), "input")
+		parser := NewParser(l)
+		parser.ParseProgram()
+
+		if !assert.NotEmpty(t, parser.Errors()) {
+			t.FailNow()
+		}
+		testError(t, parser.Errors()[0], test.expectedMessage, 1, test.expectedColumn)
+	}
+}
+
+func TestArrayLiteral(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedString string
+	}{
+		{`[]`, "[]"},
+		{`[1]`, "[1]"},
+		{`[1, 2 * 2, "three", [a]]`, `[1, (2 * 2), "three", [a]]`},
+	}
+
+	for _, test := range tests {
+		program := parseSingleStatementProgram(t, test.input)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+		array, ok := stmt.Expression.(*ast.ArrayLiteral)
+		if !assert.Truef(t, ok, "expression not of type *ast.ArrayLiteral, got=%T", stmt.Expression) {
+			t.FailNow()
+		}
+
+		assert.Equal(t, test.expectedString, array.String())
+	}
+}
+
+func TestHashLiteral(t *testing.T) {
 	assert := assert.New(t)
 
-	input := []byte(`
-return 5;
-return 10;
-return 993322;
+	program := parseSingleStatementProgram(t, `{"one": 1, "two": 1 + 1, three: [3]}`)
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !assert.Truef(ok, "expression not of type *ast.HashLiteral, got=%T", stmt.Expression) {
+		t.FailNow()
+	}
+
+	if !assert.Len(hash.Pairs, 3) {
+		t.FailNow()
+	}
+
+	assert.Equal(`"one"`, hash.Pairs[0].Key.String())
+	testIntegerLiteral(t, hash.Pairs[0].Value, 1)
+	testInfixExpression(t, hash.Pairs[1].Value, 1, "+", 1)
+	testIdentifier(t, hash.Pairs[2].Key, "three")
+	assert.Equal(`{"one": 1, "two": (1 + 1), three: [3]}`, hash.String())
+
+	empty := parseSingleStatementProgram(t, `{}`).Statements[0].(*ast.ExpressionStatement)
+	assert.Equal("{}", empty.String())
+}
+
+func TestIndexExpression(t *testing.T) {
+	program := parseSingleStatementProgram(t, `items[1 + 1]`)
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+	index, ok := stmt.Expression.(*ast.IndexExpression)
+	if !assert.Truef(t, ok, "expression not of type *ast.IndexExpression, got=%T", stmt.Expression) {
+		t.FailNow()
+	}
+
+	testIdentifier(t, index.Left, "items")
+	testInfixExpression(t, index.Index, 1, "+", 1)
+}
+
+func TestNullLiteral(t *testing.T) {
+	program := parseSingleStatementProgram(t, `null;`)
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+	literal, ok := stmt.Expression.(*ast.NullLiteral)
+	if !assert.Truef(t, ok, "expression not of type *ast.NullLiteral, got=%T", stmt.Expression) {
+		t.FailNow()
+	}
+
+	assert.Equal(t, "null", literal.String())
+}
+
+func TestOptionalIndexExpression(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedOptional bool
+		expectedString   string
+	}{
+		{`h?.name`, true, "(h?.name)"},
+		{`xs?[i + 1]`, true, "(xs?[(i + 1)])"},
+		{`h?.a?.b[0]`, false, "(((h?.a)?.b)[0])"},
+	}
+
+	for _, test := range tests {
+		assert := assert.New(t)
+
+		program := parseSingleStatementProgram(t, test.input)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+		index, ok := stmt.Expression.(*ast.IndexExpression)
+		if !assert.Truef(ok, "expression not of type *ast.IndexExpression, got=%T", stmt.Expression) {
+			t.FailNow()
+		}
+
+		assert.Equal(test.expectedOptional, index.Optional)
+		assert.Equal(test.expectedString, index.String())
+	}
+}
+
+func TestRangeExpression(t *testing.T) {
+	tests := []struct {
+		input             string
+		expectedStart     interface{}
+		expectedEnd       interface{}
+		expectedInclusive bool
+	}{
+		{`1..10`, 1, 10, false},
+		{`0..=n`, 0, "n", true},
+	}
+
+	for _, test := range tests {
+		assert := assert.New(t)
+
+		program := parseSingleStatementProgram(t, test.input)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+		expression, ok := stmt.Expression.(*ast.RangeExpression)
+		if !assert.Truef(ok, "expression not of type *ast.RangeExpression, got=%T", stmt.Expression) {
+			t.FailNow()
+		}
+
+		testLiteralExpression(t, expression.Start, test.expectedStart)
+		testLiteralExpression(t, expression.End, test.expectedEnd)
+		assert.Equal(test.expectedInclusive, expression.Inclusive)
+		assert.Equal("("+test.input+")", expression.String())
+	}
+}
+
+func TestAssignExpression(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedOperator string
+		expectedString   string
+	}{
+		{`x = 1`, "=", "(x = 1)"},
+		{`x = x + 1;`, "=", "(x = (x + 1))"},
+		{`x += 1`, "+=", "(x += 1)"},
+		{`x -= y * 2`, "-=", "(x -= (y * 2))"},
+		{`x *= 2`, "*=", "(x *= 2)"},
+		{`x /= 2`, "/=", "(x /= 2)"},
+		{`x %= 2`, "%=", "(x %= 2)"},
+		{`x = y = 3`, "=", "(x = (y = 3))"},
+		{`arr[i] = v`, "=", "((arr[i]) = v)"},
+		{`h["k"] = v || w`, "=", `((h["k"]) = (v || w))`},
+		{`m[0][1] += 1`, "+=", "(((m[0])[1]) += 1)"},
+		{`x = y ?? 0`, "=", "(x = (y ?? 0))"},
+	}
+
+	for _, test := range tests {
+		assert := assert.New(t)
+
+		program := parseSingleStatementProgram(t, test.input)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+		assignment, ok := stmt.Expression.(*ast.AssignExpression)
+		if !assert.Truef(ok, "expression not of type *ast.AssignExpression, got=%T", stmt.Expression) {
+			t.FailNow()
+		}
+
+		assert.Equal(test.expectedOperator, assignment.Operator)
+		assert.Equal(test.expectedString, assignment.String())
+	}
+}
+
+func TestAssignExpressionErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+		expectedColumn  int
+	}{
+		{`1 = 2`, "cannot assign to 1", 3},
+		{`a + b = c`, "cannot assign to (a + b)", 7},
+		{`"s" += 1`, `cannot assign to "s"`, 5},
+		{`h?.a = 1`, "cannot assign to (h?.a)", 6},
+		{`xs?[0] = 1`, "cannot assign to (xs?[0])", 8},
+	}
+
+	for _, test := range tests {
+		l := lexer.NewLexerFromBytes([]byte(test.input), "input")
+		parser := NewParser(l)
+		parser.ParseProgram()
+
+		if !assert.NotEmpty(t, parser.Errors()) {
+			t.FailNow()
+		}
+		testError(t, parser.Errors()[0], test.expectedMessage, 1, test.expectedColumn)
+	}
+}
+
+func TestIfExpression(t *testing.T) {
+	tests := []struct {
+		input          string
+		hasAlternative bool
+		expectedString string
+	}{
+		{`if (x < y) { x }`, false, "if (x < y) { x }"},
+		{`if (x < y) { x } else { y }`, true, "if (x < y) { x } else { y }"},
+		{`if (ok) {} else { let a = 1; a }`, true, "if ok {} else { let a = 1; a }"},
+	}
+
+	for _, test := range tests {
+		assert := assert.New(t)
+
+		program := parseSingleStatementProgram(t, test.input)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+		expression, ok := stmt.Expression.(*ast.IfExpression)
+		if !assert.Truef(ok, "expression not of type *ast.IfExpression, got=%T", stmt.Expression) {
+			t.FailNow()
+		}
+
+		assert.Equal(test.hasAlternative, expression.Alternative != nil)
+		assert.Equal(test.expectedString, expression.String())
+	}
+}
+
+func TestWhileExpression(t *testing.T) {
+	assert := assert.New(t)
+
+	program := parseSingleStatementProgram(t, `while (i < 10) {
+  i += 1;
+  if (i == 5) { continue; }
+  if (done) { break }
+}`)
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+	loop, ok := stmt.Expression.(*ast.WhileExpression)
+	if !assert.Truef(ok, "expression not of type *ast.WhileExpression, got=%T", stmt.Expression) {
+		t.FailNow()
+	}
+
+	testInfixExpression(t, loop.Condition, "i", "<", 10)
+	if !assert.Len(loop.Body.Statements, 3) {
+		t.FailNow()
+	}
+
+	assert.Equal(
+		"while (i < 10) { (i += 1) if (i == 5) { continue; } if done { break; } }",
+		loop.String(),
+	)
+
+	breakIf := loop.Body.Statements[2].(*ast.ExpressionStatement).Expression.(*ast.IfExpression)
+	_, ok = breakIf.Consequence.Statements[0].(*ast.BreakStatement)
+	assert.Truef(ok, "statement not of type *ast.BreakStatement, got=%T", breakIf.Consequence.Statements[0])
+}
+
+func TestForInExpression(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedKey    string
+		expectedValue  string
+		expectedString string
+	}{
+		{`for (x in xs) { sum += x }`, "", "x", "for (x in xs) { (sum += x) }"},
+		{`for (k, v in {"a": 1}) { k }`, "k", "v", `for (k, v in {"a": 1}) { k }`},
+		{`for (c in "abc") { if (c == "b") { break; } }`, "", "c", `for (c in "abc") { if (c == "b") { break; } }`},
+	}
+
+	for _, test := range tests {
+		assert := assert.New(t)
+
+		program := parseSingleStatementProgram(t, test.input)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+		loop, ok := stmt.Expression.(*ast.ForInExpression)
+		if !assert.Truef(ok, "expression not of type *ast.ForInExpression, got=%T", stmt.Expression) {
+			t.FailNow()
+		}
+
+		if test.expectedKey == "" {
+			assert.Nil(loop.Key)
+		} else {
+			testIdentifier(t, loop.Key, test.expectedKey)
+		}
+
+		testIdentifier(t, loop.Value, test.expectedValue)
+		assert.Equal(test.expectedString, loop.String())
+	}
+}
+
+func TestForInExpressionErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+		expectedColumn  int
+	}{
+		{`for x in xs {}`, "expected next token to be (, got IDENT instead", 5},
+		{`for (1 in xs) {}`, "expected next token to be IDENT, got INT instead", 6},
+		{`for (k, in xs) {}`, "expected next token to be IDENT, got IN instead", 9},
+		{`for (x of xs) {}`, "expected next token to be IN, got IDENT instead", 8},
+		{`for (x in xs) x`, "expected next token to be {, got IDENT instead", 15},
+	}
+
+	for _, test := range tests {
+		l := lexer.NewLexerFromBytes([]byte(test.input), "input")
+		parser := NewParser(l)
+		parser.ParseProgram()
+
+		if !assert.NotEmpty(t, parser.Errors()) {
+			t.FailNow()
+		}
+		testError(t, parser.Errors()[0], test.expectedMessage, 1, test.expectedColumn)
+	}
+}
+
+func TestLoopErrors(t *testing.T) {
+	input := []byte(`break;
+while (true) { if (x) { break; } }
+for (x in xs) { continue; }
+if (x) { continue; }
+while (x) { while (y) {} continue; }
+while (x) { x
 `)
-	l := lexer.NewLexer(bytes.NewReader(input), "input")
+
+	l := lexer.NewLexerFromBytes(input, "input")
 	parser := NewParser(l)
-	program := parser.ParseProgram()
+	parser.ParseProgram()
+	errors := parser.Errors()
+
+	if !assert.Len(t, errors, 3) {
+		t.FailNow()
+	}
+
+	testError(t, errors[0], "break outside of a loop", 1, 1)
+	testError(t, errors[1], "continue outside of a loop", 4, 10)
+	testError(t, errors[2], "expected } to close the block opened at 6:11, got EOF instead", 7, 1)
+}
+
+func TestBlocksScopeDeclarations(t *testing.T) {
+	input := []byte(`const LIMIT = 3;
+while (true) { let LIMIT = 4; LIMIT = 5; break; }
+while (true) { const step = 1; break; }
+let step = 2;
+step = 3;
+`)
+
+	l := lexer.NewLexerFromBytes(input, "input")
+	parser := NewParser(l)
+	parser.ParseProgram()
 	ensureNoErrors(t, parser)
+}
 
-	stmts := program.Statements
+func TestReturnStatements(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedValue  interface{}
+		expectedString string
+	}{
+		{"return 5;", 5, "return 5;"},
+		{"return x", "x", "return x;"},
+		{"return a + b;", nil, "return (a + b);"},
+		{"return;", nil, "return ;"},
+	}
+
+	for _, test := range tests {
+		assert := assert.New(t)
 
-	assert.Len(stmts, 3, "Program statements do not contain 3 statemens.")
+		l := lexer.NewLexerFromBytes([]byte(test.input), "input")
+		parser := NewParser(l)
+		program := parser.ParseProgram()
+		ensureNoErrors(t, parser)
 
-	for _, stmt := range stmts {
-		returnStmt, ok := stmt.(*ast.ReturnStatement)
+		if !assert.Len(program.Statements, 1) {
+			t.FailNow()
+		}
 
-		if !assert.Truef(ok, "stmt not *ast.ReturnStatement. got=%T", stmt) {
-			continue
+		returnStmt, ok := program.Statements[0].(*ast.ReturnStatement)
+		if !assert.Truef(ok, "stmt not *ast.ReturnStatement. got=%T", program.Statements[0]) {
+			t.FailNow()
+		}
+
+		assert.Equal("return", returnStmt.TokenLiteral())
+		if test.expectedValue != nil {
+			testLiteralExpression(t, returnStmt.ReturnValue, test.expectedValue)
+		}
+		assert.Equal(test.expectedString, returnStmt.String())
+	}
+}
+
+func TestFunctionLiteral(t *testing.T) {
+	tests := []struct {
+		input              string
+		expectedParameters []string
+		expectedString     string
+	}{
+		{`fn() {}`, []string{}, "fn() {}"},
+		{`fn(x) { x }`, []string{"x"}, "fn(x) { x }"},
+		{`fn(x, y) { return x + y; }`, []string{"x", "y"}, "fn(x, y) { return (x + y); }"},
+	}
+
+	for _, test := range tests {
+		assert := assert.New(t)
+
+		program := parseSingleStatementProgram(t, test.input)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+		function, ok := stmt.Expression.(*ast.FunctionLiteral)
+		if !assert.Truef(ok, "expression not of type *ast.FunctionLiteral, got=%T", stmt.Expression) {
+			t.FailNow()
+		}
+
+		parameters := []string{}
+		for _, parameter := range function.Parameters {
+			parameters = append(parameters, parameter.Value)
+		}
+
+		assert.Equal(test.expectedParameters, parameters)
+		assert.Equal(test.expectedString, function.String())
+	}
+}
+
+func TestCallExpression(t *testing.T) {
+	assert := assert.New(t)
+
+	program := parseSingleStatementProgram(t, `add(1, 2 * 3, x + y)`)
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+	call, ok := stmt.Expression.(*ast.CallExpression)
+	if !assert.Truef(ok, "expression not of type *ast.CallExpression, got=%T", stmt.Expression) {
+		t.FailNow()
+	}
+
+	testIdentifier(t, call.Function, "add")
+	if !assert.Len(call.Arguments, 3) {
+		t.FailNow()
+	}
+
+	testLiteralExpression(t, call.Arguments[0], 1)
+	testInfixExpression(t, call.Arguments[1], 2, "*", 3)
+	testInfixExpression(t, call.Arguments[2], "x", "+", "y")
+	assert.Equal("add(1, (2 * 3), (x + y))", call.String())
+}
+
+func TestPipeExpression(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedString string
+	}{
+		{`xs |> f`, "(xs |> f)"},
+		{`xs |> filter(isEven) |> map(double)`, "((xs |> filter(isEven)) |> map(double))"},
+		{`a + 1 |> f(b * 2)`, "((a + 1) |> f((b * 2)))"},
+		{`a ?? b |> f`, "((a ?? b) |> f)"},
+		{`x = a |> f`, "(x = (a |> f))"},
+		{`a |> fn(x) { x }`, "(a |> fn(x) { x })"},
+		{`a |> (f(b))`, "(a |> (f(b)))"},
+		{`a |> ((f))`, "(a |> f)"},
+		{`a |> (f)(b)`, "(a |> f(b))"},
+		{`a |> (b + c)`, "(a |> (b + c))"},
+	}
+
+	for _, test := range tests {
+		program := parseSingleStatementProgram(t, test.input)
+		assert.Equal(t, test.expectedString, program.String())
+
+		// the printed expression parses back to the same tree
+		reparsed := parseSingleStatementProgram(t, test.expectedString)
+		assert.Equal(t, test.expectedString, reparsed.String())
+	}
+}
+
+func TestFunctionErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{`fn(x, 1) {}`, "expected next token to be IDENT, got INT instead", 1, 7},
+		{`fn(x y) {}`, "expected next token to be ), got IDENT instead", 1, 6},
+		{`fn(x) x`, "expected next token to be {, got IDENT instead", 1, 7},
+		{`f(1, 2`, "expected next token to be ), got EOF instead", 1, 7},
+		{"while (true) {\n  fn() { break; }\n}", "break outside of a loop", 2, 10},
+		{"const C = 1;\nfn() { C = 2 }", "cannot assign to constant C, declared at 1:7", 2, 8},
+	}
+
+	for _, test := range tests {
+		l := lexer.NewLexerFromBytes([]byte(test.input), "input")
+		parser := NewParser(l)
+		parser.ParseProgram()
+
+		if !assert.NotEmptyf(t, parser.Errors(), "no errors for %q", test.input) {
+			t.FailNow()
 		}
+		testError(t, parser.Errors()[0], test.expectedMessage, test.expectedLine, test.expectedColumn)
+	}
+}
+
+func TestFunctionParametersShadowConstants(t *testing.T) {
+	l := lexer.NewLexerFromBytes([]byte("const x = 1;\nlet f = fn(x) { x = 2 };"), "input")
+	parser := NewParser(l)
+	parser.ParseProgram()
+	ensureNoErrors(t, parser)
+}
 
-		assert.Equal("return", returnStmt.TokenLiteral(), "returnStmt.TokenLiteral not 'return'")
+func TestSyntaxTreeReproducesSource(t *testing.T) {
+	inputs := []string{
+		"",
+		"   \n\t ",
+		"let a = 1;",
+		"let a = 1;\n\n  let b = a + 2 ;  \r\n",
+		"\n\tlet  x=(1 + 2) * 3;\nx\n",
+		"let a = 1\nlet a 1;\nlet 1;\n",
+		"(a + (b ) * c;\n}\n",
+		"return 5;\n\n// not a comment yet\n",
+		"#!/usr/bin/env gibbon\nlet a = 1;\n",
+		"\xEF\xBB\xBF#!/usr/bin/env gibbon\n\nlet a = 1;\n",
+		"\xEF\xBB\xBF",
+		"while (i < 3) {\n  i += 1; // trivia\n  if (i) { break }\n}\n",
+		"while (x) {\n",
+		"let y = h?.a?[ 0 ] ?? null;\n",
+		"let s = \"a ${ b +1 } c ${ {\"k\": 2} }\";\n",
+		"let f = fn( a, b ) {\n  return a |> g( b );\n};\n",
 	}
+
+	for _, input := range inputs {
+		l := lexer.NewLexerFromBytes([]byte(input), "input")
+		parser := NewLosslessParser(l)
+		tree := parser.ParseSyntaxTree()
+
+		assert.Equal(t, input, tree.String())
+	}
+}
+
+func TestSyntaxTreeStatements(t *testing.T) {
+	assert := assert.New(t)
+
+	input := []byte("let a = 1; \n  a + 2\n")
+	parser := NewLosslessParser(lexer.NewLexerFromBytes(input, "input"))
+	tree := parser.ParseSyntaxTree()
+	ensureNoErrors(t, parser)
+
+	if !assert.Len(tree.Statements, 2) {
+		t.FailNow()
+	}
+
+	testLetStatement(t, tree.Statements[0].Node, "a")
+	assert.Equal("let a = 1; \n", tree.Statements[0].String())
+	assert.Equal("  a + 2\n", tree.Statements[1].String())
+	assert.Equal("(a + 2)", tree.Statements[1].Node.String())
 }
 
 // ------HELPERS------
 
+func parseSingleStatementProgram(t *testing.T, input string) *ast.Program {
+	l := lexer.NewLexerFromBytes([]byte(input), "input")
+	parser := NewParser(l)
+	program := parser.ParseProgram()
+	ensureNoErrors(t, parser)
+
+	if !assert.Len(t, program.Statements, 1) {
+		t.FailNow()
+	}
+
+	if _, ok := program.Statements[0].(*ast.ExpressionStatement); !ok {
+		t.Fatalf("statement not of type *ast.ExpressionStatement, got=%T", program.Statements[0])
+	}
+
+	return program
+}
+
 func ensureNoErrors(t *testing.T, p *Parser) {
 	errors := p.Errors()
 
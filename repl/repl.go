@@ -2,10 +2,11 @@ package repl
 
 import (
 	"bufio"
-	"bytes"
 	"fmt"
+	"gibbon/evaluator"
 	"gibbon/lexer"
-	"gibbon/token"
+	"gibbon/object"
+	"gibbon/parser"
 	"io"
 )
 
@@ -13,6 +14,9 @@ const PROMPT = ">> "
 
 func Start(input io.Reader, output io.Writer) {
 	scanner := bufio.NewScanner(input)
+	env := object.NewEnvironment()
+	e := evaluator.NewEvaluator()
+
 	for {
 		fmt.Fprint(output, PROMPT)
 		scanned := scanner.Scan()
@@ -20,10 +24,24 @@ func Start(input io.Reader, output io.Writer) {
 			return
 		}
 
-		line := scanner.Bytes()
-		l := lexer.NewLexer(bytes.NewReader(line), "REPL")
-		for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
-			fmt.Fprintf(output, "%+v\n", tok)
+		l := lexer.NewLexerFromBytes(scanner.Bytes(), "REPL")
+		p := parser.NewParser(l)
+		program := p.ParseProgram()
+
+		if len(p.Errors()) != 0 {
+			PrintParserErrors(output, l.SourceFile(), p.Errors())
+			continue
+		}
+
+		if result := e.Eval(program, env); result != nil {
+			fmt.Fprintln(output, result.Inspect())
 		}
 	}
 }
+
+func PrintParserErrors(output io.Writer, fileName string, errors []parser.Error) {
+	for _, err := range errors {
+		location := err.Location()
+		fmt.Fprintf(output, "%s:%d:%d: %s\n", fileName, location.Line, location.FirstCharIndex, err.Message())
+	}
+}
@@ -1,6 +1,6 @@
 package token
 
-type TokenType string
+type TokenType uint8
 
 type TokenLocation struct {
 	Line           uint
@@ -8,69 +8,291 @@ type TokenLocation struct {
 }
 
 type Token struct {
-	Type     TokenType
-	Literal  string
-	Location TokenLocation
+	Type           TokenType
+	Literal        string
+	Location       TokenLocation
+	LeadingTrivia  string // source skipped before the token, only kept by lossless lexers
+	TrailingTrivia string // source skipped after the token up to its line end, only kept by lossless lexers
 }
 
 const (
 	// Special
-	ILLEGAL = "ILLEGAL"
-	EOF     = "EOF"
+	ILLEGAL TokenType = iota
+	EOF
 
 	// Identifiers + literals
-	IDENT = "IDENT"
-	INT   = "INT"
+	IDENT
+	INT
+	FLOAT
+	DECIMAL
+	STRING
+	STRING_HEAD
+	STRING_MIDDLE
+	STRING_TAIL
 
 	// Operators
-	ASSIGN    = "="
-	PLUS      = "+"
-	MINUS     = "-"
-	BANG      = "!"
-	ASTERISK  = "*"
-	SLASH     = "/"
-	LT        = "<"
-	GT        = ">"
-	LTE       = "<="
-	GTE       = ">="
-	EQUAL     = "=="
-	DIFFERENT = "!="
+	ASSIGN
+	PLUS
+	MINUS
+	BANG
+	ASTERISK
+	SLASH
+	LT
+	GT
+	LTE
+	GTE
+	EQUAL
+	DIFFERENT
+	AND
+	OR
+	PERCENT
+	POWER
+	BIT_AND
+	BIT_OR
+	BIT_XOR
+	SHIFT_LEFT
+	SHIFT_RIGHT
+	TILDE
+	PLUS_ASSIGN
+	MINUS_ASSIGN
+	ASTERISK_ASSIGN
+	SLASH_ASSIGN
+	PERCENT_ASSIGN
+	RANGE
+	RANGE_INCLUSIVE
+	NULL_COALESCE
+	OPTIONAL_DOT
+	OPTIONAL_LBRACKET
+	PIPE
 
 	// Delimiters
-	COMMA     = ","
-	SEMICOLON = ";"
-	LPAREN    = "("
-	RPAREN    = ")"
-	LBRACE    = "{"
-	RBRACE    = "}"
+	COMMA
+	SEMICOLON
+	LPAREN
+	RPAREN
+	LBRACE
+	RBRACE
+	LBRACKET
+	RBRACKET
+	COLON
 
 	// Keywords
-	FUNCTION = "FUNCTION"
-	RETURN   = "RETURN"
-	LET      = "LET"
-	IF       = "IF"
-	TRUE     = "TRUE"
-	FALSE    = "FALSE"
+	FUNCTION
+	RETURN
+	LET
+	CONST
+	IF
+	ELSE
+	WHILE
+	BREAK
+	CONTINUE
+	FOR
+	IN
+	TRUE
+	FALSE
+	NULL
+
+	tokenTypeCount
 )
 
-var keywordTypes = map[string]TokenType{
-	"fn":     FUNCTION,
-	"return": RETURN,
-	"let":    LET,
-	"if":     IF,
-	"true":   TRUE,
-	"false":  FALSE,
+type Category uint8
+
+const (
+	Special Category = iota
+	Literal
+	Operator
+	Delimiter
+	Keyword
+)
+
+// Binary operator precedences, from the loosest to the tightest binding.
+const (
+	_ int = iota
+	LowestPrecedence
+	AssignPrecedence
+	PipePrecedence
+	NullCoalescePrecedence
+	LogicalOrPrecedence
+	LogicalAndPrecedence
+	EqualsPrecedence
+	LessGreaterPrecedence
+	RangePrecedence
+	BitwiseOrPrecedence
+	BitwiseXorPrecedence
+	BitwiseAndPrecedence
+	ShiftPrecedence
+	SumPrecedence
+	ProductPrecedence
+	PrefixPrecedence
+	ExponentPrecedence
+	CallPrecedence
+	IndexPrecedence
+)
+
+type tokenInfo struct {
+	category         Category
+	name             string // name shown by String() and in error messages
+	lexeme           string // fixed spelling in source code, empty when it varies
+	precedence       int    // binary operator precedence, zero when not a binary operator
+	rightAssociative bool   // whether a chain of this binary operator groups from the right
 }
 
-var operators = [...]string{ASSIGN, BANG, LT, GT, LTE, GTE, EQUAL, DIFFERENT}
+var tokenInfos = [tokenTypeCount]tokenInfo{
+	ILLEGAL: {category: Special, name: "ILLEGAL"},
+	EOF:     {category: Special, name: "EOF"},
 
-func GetOperatorTokenType(operator string) TokenType {
-	for _, tokenType := range operators {
-		if tokenType == operator {
-			return TokenType(tokenType)
+	IDENT:   {category: Literal, name: "IDENT"},
+	INT:     {category: Literal, name: "INT"},
+	FLOAT:   {category: Literal, name: "FLOAT"},
+	DECIMAL: {category: Literal, name: "DECIMAL"},
+	STRING:  {category: Literal, name: "STRING"},
+
+	// the parts of an interpolated string around its embedded expressions
+	STRING_HEAD:   {category: Literal, name: "STRING_HEAD"},
+	STRING_MIDDLE: {category: Literal, name: "STRING_MIDDLE"},
+	STRING_TAIL:   {category: Literal, name: "STRING_TAIL"},
+
+	ASSIGN:    {category: Operator, name: "=", lexeme: "=", precedence: AssignPrecedence, rightAssociative: true},
+	PLUS:      {category: Operator, name: "+", lexeme: "+", precedence: SumPrecedence},
+	MINUS:     {category: Operator, name: "-", lexeme: "-", precedence: SumPrecedence},
+	BANG:      {category: Operator, name: "!", lexeme: "!"},
+	ASTERISK:  {category: Operator, name: "*", lexeme: "*", precedence: ProductPrecedence},
+	SLASH:     {category: Operator, name: "/", lexeme: "/", precedence: ProductPrecedence},
+	LT:        {category: Operator, name: "<", lexeme: "<", precedence: LessGreaterPrecedence},
+	GT:        {category: Operator, name: ">", lexeme: ">", precedence: LessGreaterPrecedence},
+	LTE:       {category: Operator, name: "<=", lexeme: "<=", precedence: LessGreaterPrecedence},
+	GTE:       {category: Operator, name: ">=", lexeme: ">=", precedence: LessGreaterPrecedence},
+	EQUAL:     {category: Operator, name: "==", lexeme: "==", precedence: EqualsPrecedence},
+	DIFFERENT: {category: Operator, name: "!=", lexeme: "!=", precedence: EqualsPrecedence},
+	AND:       {category: Operator, name: "&&", lexeme: "&&", precedence: LogicalAndPrecedence},
+	OR:        {category: Operator, name: "||", lexeme: "||", precedence: LogicalOrPrecedence},
+
+	PERCENT:     {category: Operator, name: "%", lexeme: "%", precedence: ProductPrecedence},
+	POWER:       {category: Operator, name: "**", lexeme: "**", precedence: ExponentPrecedence, rightAssociative: true},
+	BIT_AND:     {category: Operator, name: "&", lexeme: "&", precedence: BitwiseAndPrecedence},
+	BIT_OR:      {category: Operator, name: "|", lexeme: "|", precedence: BitwiseOrPrecedence},
+	BIT_XOR:     {category: Operator, name: "^", lexeme: "^", precedence: BitwiseXorPrecedence},
+	SHIFT_LEFT:  {category: Operator, name: "<<", lexeme: "<<", precedence: ShiftPrecedence},
+	SHIFT_RIGHT: {category: Operator, name: ">>", lexeme: ">>", precedence: ShiftPrecedence},
+	TILDE:       {category: Operator, name: "~", lexeme: "~"},
+
+	PLUS_ASSIGN:     {category: Operator, name: "+=", lexeme: "+=", precedence: AssignPrecedence, rightAssociative: true},
+	MINUS_ASSIGN:    {category: Operator, name: "-=", lexeme: "-=", precedence: AssignPrecedence, rightAssociative: true},
+	ASTERISK_ASSIGN: {category: Operator, name: "*=", lexeme: "*=", precedence: AssignPrecedence, rightAssociative: true},
+	SLASH_ASSIGN:    {category: Operator, name: "/=", lexeme: "/=", precedence: AssignPrecedence, rightAssociative: true},
+	PERCENT_ASSIGN:  {category: Operator, name: "%=", lexeme: "%=", precedence: AssignPrecedence, rightAssociative: true},
+
+	RANGE:           {category: Operator, name: "..", lexeme: "..", precedence: RangePrecedence},
+	RANGE_INCLUSIVE: {category: Operator, name: "..=", lexeme: "..=", precedence: RangePrecedence},
+
+	NULL_COALESCE:     {category: Operator, name: "??", lexeme: "??", precedence: NullCoalescePrecedence},
+	OPTIONAL_DOT:      {category: Operator, name: "?.", lexeme: "?.", precedence: IndexPrecedence},
+	OPTIONAL_LBRACKET: {category: Operator, name: "?[", lexeme: "?[", precedence: IndexPrecedence},
+	PIPE:              {category: Operator, name: "|>", lexeme: "|>", precedence: PipePrecedence},
+
+	COMMA:     {category: Delimiter, name: ",", lexeme: ","},
+	SEMICOLON: {category: Delimiter, name: ";", lexeme: ";"},
+	LPAREN:    {category: Delimiter, name: "(", lexeme: "(", precedence: CallPrecedence},
+	RPAREN:    {category: Delimiter, name: ")", lexeme: ")"},
+	LBRACE:    {category: Delimiter, name: "{", lexeme: "{"},
+	RBRACE:    {category: Delimiter, name: "}", lexeme: "}"},
+	LBRACKET:  {category: Delimiter, name: "[", lexeme: "[", precedence: IndexPrecedence},
+	RBRACKET:  {category: Delimiter, name: "]", lexeme: "]"},
+	COLON:     {category: Delimiter, name: ":", lexeme: ":"},
+
+	FUNCTION: {category: Keyword, name: "FUNCTION", lexeme: "fn"},
+	RETURN:   {category: Keyword, name: "RETURN", lexeme: "return"},
+	LET:      {category: Keyword, name: "LET", lexeme: "let"},
+	CONST:    {category: Keyword, name: "CONST", lexeme: "const"},
+	IF:       {category: Keyword, name: "IF", lexeme: "if"},
+	ELSE:     {category: Keyword, name: "ELSE", lexeme: "else"},
+	WHILE:    {category: Keyword, name: "WHILE", lexeme: "while"},
+	BREAK:    {category: Keyword, name: "BREAK", lexeme: "break"},
+	CONTINUE: {category: Keyword, name: "CONTINUE", lexeme: "continue"},
+	FOR:      {category: Keyword, name: "FOR", lexeme: "for"},
+	IN:       {category: Keyword, name: "IN", lexeme: "in", precedence: LessGreaterPrecedence},
+	TRUE:     {category: Keyword, name: "TRUE", lexeme: "true"},
+	FALSE:    {category: Keyword, name: "FALSE", lexeme: "false"},
+	NULL:     {category: Keyword, name: "NULL", lexeme: "null"},
+}
+
+func (t TokenType) String() string {
+	if t < tokenTypeCount {
+		return tokenInfos[t].name
+	}
+
+	return tokenInfos[ILLEGAL].name
+}
+
+func (t TokenType) Category() Category {
+	if t < tokenTypeCount {
+		return tokenInfos[t].category
+	}
+
+	return Special
+}
+
+// Lexeme returns the fixed source spelling of keywords, operators and
+// delimiters, and an empty string for token types whose spelling varies.
+func (t TokenType) Lexeme() string {
+	if t < tokenTypeCount {
+		return tokenInfos[t].lexeme
+	}
+
+	return ""
+}
+
+// Precedence returns the binding power of t when used as a binary operator,
+// or zero when t is not a binary operator.
+func (t TokenType) Precedence() int {
+	if t < tokenTypeCount {
+		return tokenInfos[t].precedence
+	}
+
+	return 0
+}
+
+// RightAssociative tells whether a chain of t as a binary operator, such as
+// "2 ** 3 ** 2", groups from the right.
+func (t TokenType) RightAssociative() bool {
+	if t < tokenTypeCount {
+		return tokenInfos[t].rightAssociative
+	}
+
+	return false
+}
+
+// TokenTypes returns every defined token type, in declaration order.
+func TokenTypes() []TokenType {
+	types := make([]TokenType, 0, tokenTypeCount)
+	for t := TokenType(0); t < tokenTypeCount; t++ {
+		types = append(types, t)
+	}
+
+	return types
+}
+
+var keywordTypes = lexemeTypes(Keyword)
+
+var operators = lexemeTypes(Operator)
+
+func lexemeTypes(category Category) map[string]TokenType {
+	types := make(map[string]TokenType)
+
+	for t, info := range tokenInfos {
+		if info.category == category {
+			types[info.lexeme] = TokenType(t)
 		}
 	}
 
+	return types
+}
+
+func GetOperatorTokenType(operator string) TokenType {
+	if tokenType, ok := operators[operator]; ok {
+		return tokenType
+	}
+
 	return ILLEGAL
 }
 
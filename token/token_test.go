@@ -0,0 +1,50 @@
+package token
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEveryTokenTypeHasMetadata(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, tokenType := range TokenTypes() {
+		assert.NotEmptyf(tokenType.String(), "token type %d has no name", tokenType)
+
+		if tokenType.Category() == Operator || tokenType.Category() == Delimiter || tokenType.Category() == Keyword {
+			assert.NotEmptyf(tokenType.Lexeme(), "token type %s has no lexeme", tokenType)
+		}
+	}
+}
+
+func TestTokenTypeLookup(t *testing.T) {
+	tests := []struct {
+		lexeme       string
+		lookup       func(string) TokenType
+		expectedType TokenType
+	}{
+		{"==", GetOperatorTokenType, EQUAL},
+		{"<=", GetOperatorTokenType, LTE},
+		{"!", GetOperatorTokenType, BANG},
+		{"<>", GetOperatorTokenType, ILLEGAL},
+		{"fn", GetIdentTokenType, FUNCTION},
+		{"let", GetIdentTokenType, LET},
+		{"letter", GetIdentTokenType, IDENT},
+	}
+
+	for _, test := range tests {
+		assert.Equalf(t, test.expectedType, test.lookup(test.lexeme), "wrong type for %q", test.lexeme)
+	}
+}
+
+func TestTokenTypePrecedence(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(SumPrecedence, PLUS.Precedence())
+	assert.Equal(ProductPrecedence, SLASH.Precedence())
+	assert.Equal(EqualsPrecedence, DIFFERENT.Precedence())
+	assert.Zero(BANG.Precedence())
+	assert.Zero(IDENT.Precedence())
+	assert.Equal("ILLEGAL", TokenType(255).String())
+}